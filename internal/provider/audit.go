@@ -0,0 +1,52 @@
+package provider
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// auditLogger appends one JSON line per successful mutation to a local file.
+// Secret values are never recorded.
+type auditLogger struct {
+	path string
+	mu   sync.Mutex
+}
+
+type auditEntry struct {
+	Timestamp string `json:"timestamp"`
+	Operation string `json:"operation"`
+	Namespace string `json:"namespace"`
+	Key       string `json:"key"`
+	Version   int    `json:"version"`
+	Outcome   string `json:"outcome"`
+}
+
+func (a *auditLogger) record(op, ns, key string, version int) error {
+	line, err := json.Marshal(auditEntry{
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+		Operation: op,
+		Namespace: ns,
+		Key:       key,
+		Version:   version,
+		Outcome:   "success",
+	})
+	if err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	f, err := os.OpenFile(filepath.Clean(a.path), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
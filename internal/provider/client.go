@@ -2,25 +2,134 @@ package provider
 
 import (
 	"bytes"
+	"context"
+	"crypto/sha256"
 	"crypto/tls"
 	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
+	"net"
 	"net/http"
+	"net/url"
 	"os"
+	"path"
 	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/m34l/terraform-provider-yggdrasil/internal/utils"
+	"golang.org/x/sync/singleflight"
 )
 
 type APIClient struct {
-	baseURL    string
-	hc         *http.Client
-	token      string
-	apiVersion string
+	baseURL          string
+	readBaseURL      string       // read_endpoint, or baseURL when unset
+	readHC           *http.Client // client for readBaseURL; hc when read_endpoint is unset
+	hc               *http.Client
+	token            string
+	apiVersion       string
+	retryStatusCodes map[int]bool
+	audit            *auditLogger
+	redactPatterns   []*regexp.Regexp
+	requestTimeout   time.Duration
+	latency          *latencyTracker // nil unless adaptive_timeout is set
+	metrics          *metrics        // nil unless metrics_listen_addr is set
+	nsCache          *namespaceCache // nil when namespace_cache_ttl is 0
+
+	strictParsing     bool
+	strictMu          sync.Mutex
+	unknownFieldsSeen map[string]bool // reported once per provider run
+
+	contentType string // Content-Type for request bodies
+	accept      string // Accept header, only sent when content_type is configured
+
+	defaultChangeReason string
+	keyPrefix           string
+	maxResponseBytes    int64
+	streamingThreshold  int64
+	protectedTag        string // "name" or "name=value"; empty disables the check
+	autoCreateNamespace bool
+
+	// Namespace guardrails, as path.Match patterns. Deny wins over allow;
+	// an empty allow list allows everything not denied.
+	allowedNamespaces []string
+	deniedNamespaces  []string
+
+	// protocol selects REST (default) or GraphQL for secret reads and writes.
+	protocol        string
+	graphqlEndpoint string
+
+	// assumeReadFromState keeps existing state when a read is forbidden (write-only tokens).
+	assumeReadFromState bool
+
+	noticeMu       sync.Mutex
+	noticedTagKeys map[string]bool
+
+	// reads lets concurrent identical namespace reads share one request.
+	reads singleflight.Group
+
+	// cfg is the configuration c was built from, kept to derive TLS overrides.
+	cfg         Config
+	overrideMu  sync.Mutex
+	tlsOverride map[string]*APIClient
+}
+
+// defaultRetryStatusCodes is used when retry_status_codes is not configured.
+var defaultRetryStatusCodes = []int{429, 500, 502, 503, 504}
+
+const (
+	maxRetries     = 3
+	retryBaseDelay = 500 * time.Millisecond
+
+	defaultContentType      = "application/json"
+	defaultMaxResponseBytes = 16 << 20 // 16 MiB
+	defaultRequestTimeout   = 30 * time.Second
+	connectTimeout          = 10 * time.Second
+
+	unixScheme = "unix://"
+	// unixBaseURL is the URL requests use when the endpoint is a socket; the
+	// host is ignored by the dialer.
+	unixBaseURL = "http://unix"
+)
+
+// newHTTPClient returns an HTTP client for endpoint and the base URL requests
+// to it use, which differs from endpoint for Unix sockets.
+func newHTTPClient(cfg Config, endpoint string, tlsCfg *tls.Config) (*http.Client, string) {
+	// No client-level Timeout: each operation's deadline comes from its context
+	// (see withTimeout). The dialer and TLS handshake keep their own bounds so
+	// a dead host cannot hang an operation until the overall deadline.
+	dialer := &net.Dialer{
+		Timeout:   connectTimeout,
+		KeepAlive: 30 * time.Second,
+	}
+	transport := &http.Transport{
+		TLSClientConfig:     tlsCfg,
+		DialContext:         dialer.DialContext,
+		TLSHandshakeTimeout: connectTimeout,
+	}
+
+	socketPath, ok := strings.CutPrefix(endpoint, unixScheme)
+	if !ok {
+		return &http.Client{Transport: transport}, endpoint
+	}
+	// Plain HTTP over a local socket: access is governed by the socket's
+	// file permissions, so TLS settings do not apply.
+	if cfg.InsecureSkipVerify || cfg.CACertPath != "" || cfg.ClientCertPath != "" {
+		log.Printf("[WARN] Endpoint %s is a Unix socket; TLS settings are ignored", endpoint)
+	}
+	transport.TLSClientConfig = nil
+	transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+		return dialer.DialContext(ctx, "unix", socketPath)
+	}
+	return &http.Client{Transport: transport}, unixBaseURL
 }
 
 func newClient(cfg Config) (*APIClient, error) {
@@ -46,11 +155,15 @@ func newClient(cfg Config) (*APIClient, error) {
 		tlsCfg.Certificates = []tls.Certificate{cert}
 	}
 
-	hc := &http.Client{
-		Timeout: 30 * time.Second,
-		Transport: &http.Transport{
-			TLSClientConfig: tlsCfg,
-		},
+	if len(cfg.TLSPinSHA256) > 0 {
+		tlsCfg.VerifyConnection = verifyPins(cfg.TLSPinSHA256)
+	}
+
+	hc, baseURL := newHTTPClient(cfg, cfg.Endpoint, tlsCfg)
+	readHC, readBaseURL := hc, baseURL
+	if cfg.ReadEndpoint != "" {
+		// Its own transport, so replica connections are pooled separately.
+		readHC, readBaseURL = newHTTPClient(cfg, cfg.ReadEndpoint, tlsCfg.Clone())
 	}
 
 	apiVersion := cfg.APIVersion
@@ -58,43 +171,699 @@ func newClient(cfg Config) (*APIClient, error) {
 		apiVersion = "v2" // default to v2
 	}
 
+	retryCodes := cfg.RetryStatusCodes
+	if retryCodes == nil {
+		retryCodes = defaultRetryStatusCodes
+	}
+	retryStatusCodes := make(map[int]bool, len(retryCodes))
+	for _, code := range retryCodes {
+		retryStatusCodes[code] = true
+	}
+
+	requestTimeout := cfg.RequestTimeout
+	if requestTimeout <= 0 {
+		requestTimeout = defaultRequestTimeout
+	}
+
+	var m *metrics
+	if cfg.MetricsListenAddr != "" {
+		m = providerMetrics
+	}
+
+	var nsCache *namespaceCache
+	switch {
+	case cfg.NamespaceCacheTTL == nil:
+		nsCache = newNamespaceCache(defaultNamespaceCacheTTL)
+	case *cfg.NamespaceCacheTTL > 0:
+		nsCache = newNamespaceCache(*cfg.NamespaceCacheTTL)
+	}
+
+	var latency *latencyTracker
+	if cfg.AdaptiveTimeout {
+		latency = newLatencyTracker(cfg.AdaptiveTimeoutMin, requestTimeout, cfg.AdaptiveTimeoutMax)
+	}
+
+	maxResponseBytes := cfg.MaxResponseBytes
+	if maxResponseBytes <= 0 {
+		maxResponseBytes = defaultMaxResponseBytes
+	}
+
+	streamingThreshold := cfg.StreamingThreshold
+	if streamingThreshold <= 0 {
+		streamingThreshold = defaultStreamingThreshold
+	}
+
+	contentType := cfg.ContentType
+	if contentType == "" {
+		contentType = defaultContentType
+	}
+
+	var audit *auditLogger
+	if cfg.AuditLogPath != "" {
+		audit = &auditLogger{path: cfg.AuditLogPath}
+	}
+
 	return &APIClient{
-		baseURL:    cfg.Endpoint,
-		hc:         hc,
-		token:      cfg.Token,
-		apiVersion: apiVersion,
+		baseURL:          baseURL,
+		readBaseURL:      readBaseURL,
+		readHC:           readHC,
+		hc:               hc,
+		token:            cfg.Token,
+		apiVersion:       apiVersion,
+		retryStatusCodes: retryStatusCodes,
+		audit:            audit,
+		redactPatterns:   cfg.RedactPathPatterns,
+		requestTimeout:   requestTimeout,
+		latency:          latency,
+		metrics:          m,
+		nsCache:          nsCache,
+		strictParsing:    cfg.StrictParsing,
+		contentType:      contentType,
+		accept:           cfg.ContentType,
+
+		defaultChangeReason: cfg.DefaultChangeReason,
+		keyPrefix:           cfg.KeyPrefix,
+		maxResponseBytes:    maxResponseBytes,
+		streamingThreshold:  streamingThreshold,
+		protectedTag:        cfg.ProtectedTag,
+		autoCreateNamespace: cfg.AutoCreateNamespace,
+		allowedNamespaces:   cfg.AllowedNamespaces,
+		deniedNamespaces:    cfg.DeniedNamespaces,
+		assumeReadFromState: cfg.AssumeReadFromState,
+		protocol:            cfg.Protocol,
+		graphqlEndpoint:     cfg.GraphQLEndpoint,
+		cfg:                 cfg,
 	}, nil
 }
 
+// withTLS returns a client that differs from c only in its TLS verification
+// settings. Derived clients are cached, so a resource that overrides TLS
+// reuses one connection pool instead of dialing per operation, and they
+// write to c's audit log.
+func (c *APIClient) withTLS(insecureSkipVerify bool, caCertPath string) (*APIClient, error) {
+	if insecureSkipVerify == c.cfg.InsecureSkipVerify && caCertPath == c.cfg.CACertPath {
+		return c, nil
+	}
+	c.overrideMu.Lock()
+	defer c.overrideMu.Unlock()
+
+	cacheKey := fmt.Sprintf("%t|%s", insecureSkipVerify, caCertPath)
+	if derived, ok := c.tlsOverride[cacheKey]; ok {
+		return derived, nil
+	}
+	cfg := c.cfg
+	cfg.InsecureSkipVerify = insecureSkipVerify
+	cfg.CACertPath = caCertPath
+	cfg.AuditLogPath = ""
+	derived, err := newClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	derived.audit = c.audit
+	derived.latency = c.latency
+	// Share the cache so writes through either client invalidate it.
+	derived.nsCache = c.nsCache
+	if c.tlsOverride == nil {
+		c.tlsOverride = map[string]*APIClient{}
+	}
+	c.tlsOverride[cacheKey] = derived
+	return derived, nil
+}
+
+// unnoticedKeys returns the keys not yet passed to it during this provider run,
+// so informational diagnostics about them are only emitted once.
+func (c *APIClient) unnoticedKeys(keys []string) []string {
+	c.noticeMu.Lock()
+	defer c.noticeMu.Unlock()
+	if c.noticedTagKeys == nil {
+		c.noticedTagKeys = map[string]bool{}
+	}
+	var out []string
+	for _, k := range keys {
+		if !c.noticedTagKeys[k] {
+			c.noticedTagKeys[k] = true
+			out = append(out, k)
+		}
+	}
+	return out
+}
+
+// Prewarm opens a connection to the endpoint with a cheap unauthenticated GET
+// so the first real operation reuses it from the transport's idle pool
+// instead of paying for connection setup and the TLS handshake.
+func (c *APIClient) Prewarm(ctx context.Context) error {
+	ctx, cancel := c.withTimeout(ctx, "prewarm")
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", c.baseURL+"/", nil)
+	if err != nil {
+		return err
+	}
+	start := time.Now()
+	res, err := c.hc.Do(req)
+	if err != nil {
+		return err
+	}
+	// The body must be fully read for the connection to return to the pool.
+	_, _ = io.Copy(io.Discard, res.Body)
+	res.Body.Close()
+	log.Printf("[DEBUG] Prewarmed connection to %s in %s (%s)", c.safeURL(c.baseURL), time.Since(start), statusDesc(res))
+	return nil
+}
+
+// fullKey returns key with the configured key_prefix prepended.
+func (c *APIClient) fullKey(key string) string {
+	return c.keyPrefix + key
+}
+
+// normalizeNamespace trims surrounding slashes and collapses repeated ones,
+// so "/team//prod/" and "team/prod" address the same namespace.
+func normalizeNamespace(ns string) string {
+	parts := strings.FieldsFunc(ns, func(r rune) bool { return r == '/' })
+	return strings.Join(parts, "/")
+}
+
+// escapeNamespace returns ns for use in a URL path, escaping each segment of
+// a hierarchical namespace but keeping the slashes between them.
+func escapeNamespace(ns string) string {
+	parts := strings.Split(normalizeNamespace(ns), "/")
+	for i, p := range parts {
+		parts[i] = url.PathEscape(p)
+	}
+	return strings.Join(parts, "/")
+}
+
+// resolveNamespace normalizes ns and enforces allowed_namespaces and
+// denied_namespaces on the result. Every client method that targets a
+// namespace calls it before sending anything and uses the returned name.
+func (c *APIClient) resolveNamespace(ns string) (string, error) {
+	ns = normalizeNamespace(ns)
+	return ns, c.checkNamespace(ns)
+}
+
+func (c *APIClient) checkNamespace(ns string) error {
+	for _, pattern := range c.deniedNamespaces {
+		if ok, _ := path.Match(pattern, ns); ok {
+			return fmt.Errorf("namespace %q is blocked by the provider's denied_namespaces (pattern %q)", ns, pattern)
+		}
+	}
+	if len(c.allowedNamespaces) == 0 {
+		return nil
+	}
+	for _, pattern := range c.allowedNamespaces {
+		if ok, _ := path.Match(pattern, ns); ok {
+			return nil
+		}
+	}
+	return fmt.Errorf("namespace %q is not in the provider's allowed_namespaces %v", ns, c.allowedNamespaces)
+}
+
+// setHeaders applies content negotiation headers, any per-operation headers
+// attached to the request context, and finally auth, so auth always wins.
+func (c *APIClient) setHeaders(req *http.Request, hasBody bool) {
+	if hasBody {
+		req.Header.Set("Content-Type", c.contentType)
+	}
+	if c.accept != "" {
+		req.Header.Set("Accept", c.accept)
+	}
+	if h, ok := req.Context().Value(headersKey{}).(map[string]string); ok {
+		for k, v := range h {
+			req.Header.Set(k, v)
+		}
+	}
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		if h, ok := req.Context().Value(writeHeadersKey{}).(map[string]string); ok {
+			for k, v := range h {
+				req.Header.Set(k, v)
+			}
+		}
+	}
+	if c.token != "" {
+		req.Header.Set("token", c.token)
+	}
+}
+
+type headersKey struct{}
+
+// withHeaders returns a context whose API requests carry h in addition to any
+// headers already attached to ctx. Empty values are skipped.
+func withHeaders(ctx context.Context, h map[string]string) context.Context {
+	merged := map[string]string{}
+	if prev, ok := ctx.Value(headersKey{}).(map[string]string); ok {
+		for k, v := range prev {
+			merged[k] = v
+		}
+	}
+	for k, v := range h {
+		if v != "" {
+			merged[k] = v
+		}
+	}
+	return context.WithValue(ctx, headersKey{}, merged)
+}
+
+type writeHeadersKey struct{}
+
+// withWriteHeaders returns a context whose mutating API requests (anything
+// but GET and HEAD) carry h, on top of the headers from withHeaders. Empty
+// values are skipped.
+func withWriteHeaders(ctx context.Context, h map[string]string) context.Context {
+	merged := map[string]string{}
+	for k, v := range h {
+		if v != "" {
+			merged[k] = v
+		}
+	}
+	return context.WithValue(ctx, writeHeadersKey{}, merged)
+}
+
+// detachedHeaders returns a new context carrying only the request headers of
+// ctx, for cleanup requests that must be sent even after ctx was cancelled or
+// its deadline (including a timeouts block's, see withTimeout) has passed.
+func detachedHeaders(ctx context.Context) context.Context {
+	out := context.Background()
+	if h, ok := ctx.Value(headersKey{}).(map[string]string); ok {
+		out = context.WithValue(out, headersKey{}, h)
+	}
+	if h, ok := ctx.Value(writeHeadersKey{}).(map[string]string); ok {
+		out = context.WithValue(out, writeHeadersKey{}, h)
+	}
+	return out
+}
+
+// encryptionContextHeader carries the envelope-encryption context (AAD) as
+// base64-encoded JSON with sorted keys, so equal maps give equal headers.
+const encryptionContextHeader = "X-Encryption-Context"
+
+// withEncryptionContext returns a context whose API requests carry ec as the
+// encryption context. An empty map leaves ctx unchanged.
+func withEncryptionContext(ctx context.Context, ec map[string]string) context.Context {
+	if len(ec) == 0 {
+		return ctx
+	}
+	b, _ := json.Marshal(ec)
+	return withHeaders(ctx, map[string]string{encryptionContextHeader: base64.StdEncoding.EncodeToString(b)})
+}
+
+// headersFlightKey identifies the per-operation headers on ctx, so reads that
+// send different headers (e.g. encryption contexts) are never deduplicated.
+// The headers are hashed, as they may carry credentials and the flight key is
+// logged.
+func headersFlightKey(ctx context.Context) string {
+	h, _ := ctx.Value(headersKey{}).(map[string]string)
+	if len(h) == 0 {
+		return ""
+	}
+	b, _ := json.Marshal(h)
+	sum := sha256.Sum256(b)
+	return "headers=" + hex.EncodeToString(sum[:])
+}
+
+// safeURL returns url with sensitive query parameters and path segments masked for logging.
+func (c *APIClient) safeURL(url string) string {
+	return utils.RedactURLPath(utils.RedactURLQuery(url), c.redactPatterns...)
+}
+
+// Audit records a successful mutation in the audit log, if one is configured.
+func (c *APIClient) Audit(op, ns, key string, version int) error {
+	if c.audit == nil {
+		return nil
+	}
+	return c.audit.record(op, ns, key, version)
+}
+
+// do sends req, retrying with exponential backoff while the response status
+// is in the configured retryable set.
+func (c *APIClient) do(req *http.Request) (*http.Response, error) {
+	return c.doWith(c.hc, req)
+}
+
+type noRetryKey struct{}
+
+// withoutRetries returns ctx for a request that must be sent at most once:
+// repeating it after an error response could apply it twice.
+func withoutRetries(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noRetryKey{}, true)
+}
+
+// doWith is do using hc, e.g. the read replica's client.
+func (c *APIClient) doWith(hc *http.Client, req *http.Request) (*http.Response, error) {
+	noRetry, _ := req.Context().Value(noRetryKey{}).(bool)
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+
+		start := time.Now()
+		res, err := hc.Do(req)
+		if c.metrics != nil {
+			status := 0
+			if err == nil {
+				status = res.StatusCode
+			}
+			c.metrics.observe(opFromContext(req.Context()), status, time.Since(start))
+		}
+		if err != nil {
+			recordOutcome(req.Context(), 0)
+			return nil, err
+		}
+		if noRetry || attempt >= maxRetries || !c.retryStatusCodes[res.StatusCode] {
+			recordOutcome(req.Context(), res.StatusCode)
+			return res, nil
+		}
+		if c.metrics != nil {
+			c.metrics.retry(opFromContext(req.Context()))
+		}
+
+		_, _ = io.Copy(io.Discard, res.Body)
+		res.Body.Close()
+
+		delay := retryBaseDelay << attempt
+		log.Printf("[WARN] %s %s returned status %d, retrying in %s (attempt %d/%d)",
+			req.Method, c.safeURL(req.URL.String()), res.StatusCode, delay, attempt+1, maxRetries)
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
 type SecretPayload struct {
 	Namespace string            `json:"namespace"`
 	Key       string            `json:"key"`
 	Value     string            `json:"value"`
 	Tags      map[string]string `json:"tags,omitempty"`
+	Labels    map[string]string `json:"labels,omitempty"`
+
+	// Generate asks the server to mint the value; Value is ignored.
+	Generate bool `json:"-"`
 }
 
 type SecretResponse struct {
 	Namespace string            `json:"namespace"`
 	Key       string            `json:"key"`
 	Value     string            `json:"value"`
-	Version   int               `json:"version"`
+	Version   int               `json:"version"` // zero when not reported
 	Tags      map[string]string `json:"tags,omitempty"`
-	UpdatedAt string            `json:"updated_at"`
+	Labels    map[string]string `json:"labels,omitempty"`
+	UpdatedAt string            `json:"updated_at"` // empty when not reported
+
+	// Headers holds the HTTP response headers of the read that produced this secret.
+	Headers http.Header `json:"-"`
+}
+
+// upsertRequest is the PUT request body. Its encoding is canonical: fields
+// are written in declaration order and encoding/json writes map keys sorted,
+// so the same secret, tags and labels always produce the same bytes (request
+// signing proxies hash the raw body).
+type upsertRequest struct {
+	Configs  map[string]string `json:"configs"`
+	Generate []string          `json:"generate,omitempty"` // keys whose value the server generates
+	Labels   map[string]string `json:"labels,omitempty"`
+	Tags     map[string]string `json:"tags,omitempty"`
+}
+
+// upsertResponse is the PUT response body. updated_at is kept raw because
+// server versions disagree on its format (see normalizeTimestamp).
+type upsertResponse struct {
+	Configs   map[string]interface{} `json:"configs"` // carries generated values
+	Version   int                    `json:"version"`
+	Tags      map[string]string      `json:"tags"`
+	Labels    map[string]string      `json:"labels"`
+	UpdatedAt json.RawMessage        `json:"updated_at"`
+}
+
+// normalizeTimestamp converts a server timestamp to RFC3339. It accepts
+// RFC3339/RFC3339Nano strings and unix epochs in seconds or milliseconds,
+// given as JSON numbers or numeric strings. Anything else is returned as-is
+// rather than failing the read. An absent or null value yields "".
+func normalizeTimestamp(raw json.RawMessage) string {
+	s := strings.TrimSpace(string(raw))
+	if s == "" || s == "null" {
+		return ""
+	}
+	if unquoted, err := strconv.Unquote(s); err == nil {
+		s = unquoted
+	}
+	for _, layout := range []string{time.RFC3339, time.RFC3339Nano} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.UTC().Format(time.RFC3339)
+		}
+	}
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		// Anything past year 5138 in seconds is treated as milliseconds.
+		if n > 1e11 {
+			return time.UnixMilli(n).UTC().Format(time.RFC3339)
+		}
+		return time.Unix(n, 0).UTC().Format(time.RFC3339)
+	}
+	log.Printf("[DEBUG] Unrecognized updated_at format %q, storing it unchanged", s)
+	return s
+}
+
+func (c *APIClient) GetSecret(ctx context.Context, ns, key string) (*SecretResponse, error) {
+	return c.getSecret(ctx, ns, key, readOptions{})
+}
+
+// GetSecretVersion reads key as it was at the given namespace version.
+func (c *APIClient) GetSecretVersion(ctx context.Context, ns, key string, version int) (*SecretResponse, error) {
+	out, err := c.getSecret(ctx, ns, key, readOptions{ref: strconv.Itoa(version)})
+	if err != nil || out == nil {
+		return out, err
+	}
+	out.Version = version
+	return out, nil
+}
+
+// minVersionPollInterval is how often GetSecretMinVersion re-reads.
+const minVersionPollInterval = 2 * time.Second
+
+// errMinVersion is returned by GetSecretMinVersion when the version cannot be
+// observed or was not reached in time.
+var errMinVersion = errors.New("minimum version not available")
+
+// GetSecretMinVersion reads key once its namespace has reached at least
+// minVersion, re-reading for up to wait while the endpoint (e.g. a replica)
+// still serves an older version or does not have the key yet. It fails when
+// the server reports no namespace version, as progress cannot be observed.
+func (c *APIClient) GetSecretMinVersion(ctx context.Context, ns, key string, minVersion int, wait time.Duration) (*SecretResponse, error) {
+	deadline := time.Now().Add(wait)
+	seen := 0
+	for {
+		// A cached read would keep returning the version seen first.
+		out, err := c.getSecret(ctx, ns, key, readOptions{noCache: true})
+		if err != nil {
+			return nil, err
+		}
+		if out != nil {
+			md := metadataFromHeader(ns, out.Headers)
+			if md.Version == 0 {
+				return nil, fmt.Errorf("%w: the server did not report a namespace version (expected one of the %s headers)", errMinVersion, strings.Join(versionHeaders, ", "))
+			}
+			if md.Version >= minVersion {
+				out.Version = md.Version
+				return out, nil
+			}
+			seen = md.Version
+		}
+		if time.Now().After(deadline) {
+			if seen == 0 {
+				return nil, fmt.Errorf("%w: %s/%s did not become readable within %s (waiting for version %d)", errMinVersion, ns, key, wait, minVersion)
+			}
+			return nil, fmt.Errorf("%w: %s/%s did not reach version %d within %s (latest seen: %d)", errMinVersion, ns, key, minVersion, wait, seen)
+		}
+		log.Printf("[DEBUG] %s/%s is at version %d, waiting for %d", ns, key, seen, minVersion)
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(minVersionPollInterval):
+		}
+	}
+}
+
+// GetSecretAsOf reads key as it was at time t. Servers without time-travel
+// reads yield an error rather than the latest value.
+func (c *APIClient) GetSecretAsOf(ctx context.Context, ns, key string, t time.Time) (*SecretResponse, error) {
+	opts := readOptions{asOf: t.UTC().Format(time.RFC3339)}
+	if c.protocol == protocolGraphQL {
+		return c.graphqlGetSecret(ctx, ns, key, opts)
+	}
+	read, err := c.readNamespace(ctx, ns, opts)
+	if err != nil {
+		return nil, err
+	}
+	if read == nil {
+		// Servers without time-travel reads 404 the whole at/ route. Tell that
+		// apart from a namespace that did not exist at t by reading it as it is
+		// now: if that works, the 404 was the route.
+		latest, err := c.readNamespace(ctx, ns, readOptions{})
+		if err != nil {
+			return nil, err
+		}
+		if latest != nil {
+			return nil, fmt.Errorf("the server does not support reads as of a timestamp (reading %s as of %s returned 404 Not Found, but the namespace exists)", normalizeNamespace(ns), opts.asOf)
+		}
+		return nil, nil
+	}
+	return secretFromRead(ns, c.fullKey(key), read), nil
+}
+
+// GetSecretResolved reads key with "$ref: ns/key" references dereferenced by the server.
+func (c *APIClient) GetSecretResolved(ctx context.Context, ns, key string) (*SecretResponse, error) {
+	out, err := c.getSecret(ctx, ns, key, readOptions{resolveRefs: true})
+	if err != nil || out == nil {
+		return out, err
+	}
+	if strings.HasPrefix(strings.TrimSpace(out.Value), refPrefix) {
+		return nil, fmt.Errorf("reference in %s/%s was not resolved by the server (%s)", ns, key, strings.TrimSpace(out.Value))
+	}
+	return out, nil
 }
 
-func (c *APIClient) GetSecret(ns, key string) (*SecretResponse, error) {
-	// GET /v2/configurations/:namespace/latest/all
-	url := fmt.Sprintf("%s/%s/configurations/%s/latest/all", c.baseURL, c.apiVersion, ns)
-	safeURL := utils.RedactURLQuery(url)
+// refPrefix marks a value that points at another secret.
+const refPrefix = "$ref:"
+
+// readOptions selects which variant of the namespace read getSecret performs.
+type readOptions struct {
+	ref         string // "latest" (default) or a version number
+	asOf        string // RFC3339 timestamp; overrides ref
+	resolveRefs bool
+	keepRaw     bool // buffer the body for namespaceRead.raw even above streaming_threshold_bytes
+	primary     bool // read from endpoint even when read_endpoint is set
+	noCache     bool // skip cached reads, e.g. when polling for a newer version
+}
+
+func (c *APIClient) getSecret(ctx context.Context, ns, key string, opts readOptions) (*SecretResponse, error) {
+	if c.protocol == protocolGraphQL {
+		return c.graphqlGetSecret(ctx, ns, key, opts)
+	}
+	key = c.fullKey(key)
+
+	read, err := c.readNamespace(ctx, ns, opts)
+	if err != nil || read == nil {
+		return nil, err
+	}
+	return secretFromRead(ns, key, read), nil
+}
+
+// secretFromRead extracts key from a namespace read, or returns nil if the
+// namespace does not hold it. The /all response has no per-key metadata, so
+// Version and UpdatedAt come from the namespace's version and Last-Modified
+// headers and are left zero when the server sends neither.
+func secretFromRead(ns, key string, read *namespaceRead) *SecretResponse {
+	if val, ok := read.configs[key]; ok {
+		md := metadataFromHeader(ns, read.header)
+		return &SecretResponse{
+			Namespace: normalizeNamespace(ns),
+			Key:       key,
+			Value:     fmt.Sprintf("%v", val),
+			Version:   md.Version,
+			UpdatedAt: md.UpdatedAt,
+			Tags:      read.tags[key],
+			Labels:    read.labels[key],
+			Headers:   read.header,
+		}
+	}
+	return nil
+}
+
+// namespaceRead is the decoded result of one namespace /all read. It may be
+// shared between concurrent callers and must not be modified.
+type namespaceRead struct {
+	configs map[string]interface{}
+	labels  map[string]map[string]string // per key; nil unless the server sent them
+	tags    map[string]map[string]string // per key; nil unless the server sent them
+	header  http.Header
+	raw     []byte // response body as received; nil when it was streamed
+}
+
+// readNamespace fetches every key of ns, or returns nil if the namespace does
+// not exist. Concurrent calls for the same namespace and options share a
+// single request, and its result or error, via c.reads.
+func (c *APIClient) readNamespace(ctx context.Context, ns string, opts readOptions) (*namespaceRead, error) {
+	if err := c.restOnly("reading a whole namespace"); err != nil {
+		return nil, err
+	}
+	ns, err := c.resolveNamespace(ns)
+	if err != nil {
+		return nil, err
+	}
+	ref := opts.ref
+	if ref == "" {
+		ref = "latest"
+	}
+	if opts.asOf != "" {
+		ref = "at/" + opts.asOf
+	}
+	flightKey := fmt.Sprintf("%s@%s resolve_refs=%t raw=%t primary=%t %s", ns, ref, opts.resolveRefs, opts.keepRaw, opts.primary, headersFlightKey(ctx))
+	// Verification reads must see the primary's current state.
+	useCache := c.nsCache != nil && !opts.primary
+	if useCache && !opts.noCache {
+		if read, ok := c.nsCache.get(flightKey); ok {
+			log.Printf("[DEBUG] Cached read of %s", flightKey)
+			return read, nil
+		}
+	}
+	// The fetch serves every caller waiting on flightKey, so it must not be
+	// cancelled with the caller that happened to start it; withTimeout in
+	// fetchNamespace still bounds it. Each caller stops waiting when its own
+	// ctx is done.
+	fetchCtx := context.WithoutCancel(ctx)
+	ch := c.reads.DoChan(flightKey, func() (interface{}, error) {
+		var gen uint64
+		if useCache {
+			gen = c.nsCache.generation(ns)
+		}
+		read, err := c.fetchNamespace(fetchCtx, ns, ref, opts)
+		if err == nil && useCache {
+			c.nsCache.put(ns, flightKey, gen, read)
+		}
+		return read, err
+	})
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res := <-ch:
+		if res.Shared {
+			log.Printf("[DEBUG] Shared in-flight read of %s", flightKey)
+		}
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		return res.Val.(*namespaceRead), nil
+	}
+}
+
+func (c *APIClient) fetchNamespace(ctx context.Context, ns, ref string, opts readOptions) (*namespaceRead, error) {
+	ctx, cancel := c.withTimeout(ctx, "read")
+	defer cancel()
+
+	// GET /v2/configurations/:namespace/:version/all
+	// GET /v2/configurations/:namespace/at/:timestamp/all
+	baseURL, hc := c.readBaseURL, c.readHC
+	if opts.primary {
+		baseURL, hc = c.baseURL, c.hc
+	}
+	url := fmt.Sprintf("%s/%s/configurations/%s/%s/all", baseURL, c.apiVersion, escapeNamespace(ns), ref)
+	if opts.resolveRefs {
+		url += "?resolve_refs=true"
+	}
+	safeURL := c.safeURL(url)
 	log.Printf("[DEBUG] GET request to: %s", safeURL)
 
-	req, _ := http.NewRequest("GET", url, nil)
-	req.Header.Set("token", c.token)
+	req, _ := http.NewRequestWithContext(ctx, "GET", url, nil)
+	c.setHeaders(req, false)
 
 	// Log safe version of headers
 	log.Printf("[DEBUG] Request headers: %v", utils.RedactHTTPHeaders(req.Header))
 
-	res, err := c.hc.Do(req)
+	res, err := c.doWith(hc, req)
 	if err != nil {
 		log.Printf("[ERROR] HTTP request failed: %v", err)
 		return nil, fmt.Errorf("HTTP request failed: %w", err)
@@ -107,60 +876,198 @@ func (c *APIClient) GetSecret(ns, key string) (*SecretResponse, error) {
 		return nil, nil
 	}
 	if res.StatusCode >= 300 {
-		b, _ := io.ReadAll(res.Body)
+		b, _ := c.readBody(res)
 		safeBody := utils.RedactBytesChain(b)
-		log.Printf("[ERROR] Get secret failed (status %d): %s", res.StatusCode, string(safeBody))
-		return nil, fmt.Errorf("get secret failed (status %d): %s", res.StatusCode, string(b))
+		log.Printf("[ERROR] Get secret failed (%s): %s", statusDesc(res), utils.LogPreview(safeBody))
+		if opts.asOf != "" && (res.StatusCode == 405 || res.StatusCode == 501) {
+			return nil, fmt.Errorf("the server does not support reads as of a timestamp (%s)", statusDesc(res))
+		}
+		if opts.resolveRefs && (res.StatusCode == 409 || res.StatusCode == 422) {
+			// The server rejects circular or dangling references with a conflict/unprocessable status.
+			return nil, newAPIError(fmt.Sprintf("resolving references in %s", ns), res, b)
+		}
+		return nil, newAPIError("get secret", res, b)
 	}
 
-	b, _ := io.ReadAll(res.Body)
-	safeBody := utils.RedactBytesChain(b)
-	log.Printf("[DEBUG] Response body: %s", string(safeBody))
-
 	// Parse the response and extract the specific key
 	var configs map[string]interface{}
+	if !opts.keepRaw && res.ContentLength >= c.streamingThreshold {
+		log.Printf("[DEBUG] Response body: streamed (%d bytes, not logged)", res.ContentLength)
+		if err := c.decodeBody(res, &configs); err != nil {
+			log.Printf("[ERROR] Failed to decode JSON response: %v", err)
+			return nil, err
+		}
+		read := unwrapNamespace(configs)
+		read.header = res.Header
+		return read, nil
+	}
+
+	b, err := c.readBody(res)
+	if err != nil {
+		log.Printf("[ERROR] Failed to read response body: %v", err)
+		return nil, err
+	}
+	safeBody := utils.RedactBytesChain(b)
+	log.Printf("[DEBUG] Response body: %s", utils.LogPreview(safeBody))
+
 	if err := json.Unmarshal(b, &configs); err != nil {
 		log.Printf("[ERROR] Failed to decode JSON response: %v", err)
 		return nil, fmt.Errorf("failed to decode response: %w (body: %s)", err, string(safeBody))
 	}
 
-	// Extract the specific key from configs
-	if val, ok := configs[key]; ok {
-		return &SecretResponse{
-			Namespace: ns,
-			Key:       key,
-			Value:     fmt.Sprintf("%v", val),
-			Version:   1, // Placeholder
-			UpdatedAt: time.Now().Format(time.RFC3339),
-		}, nil
+	read := unwrapNamespace(configs)
+	read.header, read.raw = res.Header, b
+	return read, nil
+}
+
+// configsWrappers are the object fields, outermost first, that some servers
+// wrap the key/value map in, e.g. {"data": {"configs": {...}}}.
+var configsWrappers = []string{"data", "configs"}
+
+// unwrapNamespace decodes an /all response, descending into any wrapper
+// objects for the key/value map. Servers that wrap it in "configs" may send
+// per-key labels and tags beside it:
+//
+//	{"configs": {"k": "v"}, "labels": {"k": {...}}, "tags": {"k": {...}}}
+//
+// A flat map is taken as keys and values only, since "labels" or "tags" could
+// be keys in it.
+func unwrapNamespace(m map[string]interface{}) *namespaceRead {
+	read := &namespaceRead{}
+	for _, field := range configsWrappers {
+		inner, ok := m[field].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if field == "configs" {
+			read.labels = perKeyStrings(m["labels"])
+			read.tags = perKeyStrings(m["tags"])
+		}
+		m = inner
+	}
+	read.configs = m
+	return read
+}
+
+// perKeyStrings converts a {"key": {"name": "value"}} object, ignoring
+// anything that is not shaped like one.
+func perKeyStrings(v interface{}) map[string]map[string]string {
+	obj, ok := v.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	out := make(map[string]map[string]string, len(obj))
+	for key, fields := range obj {
+		fm, ok := fields.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		out[key] = make(map[string]string, len(fm))
+		for name, val := range fm {
+			if s, ok := val.(string); ok {
+				out[key][name] = s
+			}
+		}
 	}
+	return out
+}
 
-	return nil, nil
+// UpsertSecret writes p. With auto_create_namespace, a 404 from the write
+// creates the namespace and retries the write once.
+func (c *APIClient) UpsertSecret(ctx context.Context, p SecretPayload) (*SecretResponse, error) {
+	ns, err := c.resolveNamespace(p.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	p.Namespace = ns
+	defer c.nsCache.invalidate(ns)
+	if c.protocol == protocolGraphQL {
+		return c.graphqlUpsertSecret(ctx, p)
+	}
+	out, err := c.upsertSecret(ctx, p)
+	if err == nil || !c.autoCreateNamespace || errorStatus(err) != 404 {
+		return out, err
+	}
+	log.Printf("[DEBUG] Namespace %s not found on upsert, creating it (auto_create_namespace)", p.Namespace)
+	if nsErr := c.CreateNamespace(ctx, p.Namespace); nsErr != nil {
+		return nil, fmt.Errorf("upsert secret: namespace %q does not exist and creating it failed: %w", p.Namespace, nsErr)
+	}
+	return c.upsertSecret(ctx, p)
+}
+
+// GetNamespaceTags returns the default tags of ns. A namespace without tags,
+// or one that does not exist, yields nil.
+func (c *APIClient) GetNamespaceTags(ctx context.Context, ns string) (map[string]string, error) {
+	ns, err := c.resolveNamespace(ns)
+	if err != nil {
+		return nil, err
+	}
+	// GET /v2/namespaces/:namespace
+	url := fmt.Sprintf("%s/%s/namespaces/%s", c.baseURL, c.apiVersion, escapeNamespace(ns))
+	var out struct {
+		Tags map[string]string `json:"tags"`
+	}
+	if _, err := c.doJSON(ctx, "get namespace", "GET", url, nil, &out); err != nil {
+		return nil, err
+	}
+	return out.Tags, nil
 }
 
-func (c *APIClient) UpsertSecret(p SecretPayload) (*SecretResponse, error) {
+// CreateNamespace creates ns. A namespace that already exists is not an error.
+func (c *APIClient) CreateNamespace(ctx context.Context, ns string) error {
+	ns, err := c.resolveNamespace(ns)
+	if err != nil {
+		return err
+	}
+	defer c.nsCache.invalidate(ns)
+	// PUT /v2/namespaces/:namespace
+	url := fmt.Sprintf("%s/%s/namespaces/%s", c.baseURL, c.apiVersion, escapeNamespace(ns))
+	found, err := c.doJSON(ctx, "create namespace", "PUT", url, map[string]string{"name": ns}, nil)
+	if errorStatus(err) == 409 {
+		return nil
+	}
+	if err == nil && !found {
+		return fmt.Errorf("create namespace failed: the server has no namespace endpoint (status 404)")
+	}
+	return err
+}
+
+func (c *APIClient) upsertSecret(ctx context.Context, p SecretPayload) (*SecretResponse, error) {
+	ctx, cancel := c.withTimeout(ctx, "write")
+	defer cancel()
+
+	p.Key = c.fullKey(p.Key)
+
 	// PUT /v2/configurations/:namespace
-	url := fmt.Sprintf("%s/%s/configurations/%s", c.baseURL, c.apiVersion, p.Namespace)
-	safeURL := utils.RedactURLQuery(url)
+	url := fmt.Sprintf("%s/%s/configurations/%s", c.baseURL, c.apiVersion, escapeNamespace(p.Namespace))
+	safeURL := c.safeURL(url)
 	log.Printf("[DEBUG] PUT request to: %s", safeURL)
 
 	// Build the payload in the format Yggdrasil expects
-	payload := map[string]interface{}{
-		"configs": map[string]string{
-			p.Key: p.Value,
-		},
+	payload := upsertRequest{
+		Configs: map[string]string{p.Key: p.Value},
+		Labels:  p.Labels,
+		Tags:    p.Tags,
 	}
-	if len(p.Tags) > 0 {
-		payload["tags"] = p.Tags
+	if p.Generate {
+		payload.Configs = map[string]string{}
+		payload.Generate = []string{p.Key}
 	}
 
-	body, _ := json.Marshal(payload)
-	safeBody := utils.RedactBytesChain(body)
-	log.Printf("[DEBUG] Request body: %s", string(safeBody))
-
-	req, _ := http.NewRequest("PUT", url, bytes.NewReader(body))
-	req.Header.Set("token", c.token)
-	req.Header.Set("Content-Type", "application/json")
+	var req *http.Request
+	if !p.Generate && int64(len(p.Value)) >= c.streamingThreshold {
+		log.Printf("[DEBUG] Request body: streamed (%d byte value, not logged)", len(p.Value))
+		getBody := func() (io.ReadCloser, error) { return streamUpsertBody(p), nil }
+		body, _ := getBody()
+		req, _ = http.NewRequestWithContext(ctx, "PUT", url, body)
+		req.GetBody = getBody
+	} else {
+		body, _ := json.Marshal(payload)
+		safeBody := utils.RedactBytesChain(body)
+		log.Printf("[DEBUG] Request body: %s", utils.LogPreview(safeBody))
+		req, _ = http.NewRequestWithContext(ctx, "PUT", url, bytes.NewReader(body))
+	}
+	c.setHeaders(req, true)
 
 	// Log safe version of headers
 	safeHeaders := utils.RedactHTTPHeaders(req.Header)
@@ -173,7 +1080,7 @@ func (c *APIClient) UpsertSecret(p SecretPayload) (*SecretResponse, error) {
 		log.Printf("[DEBUG] Token length: %d, preview: %s...", len(c.token), c.token[:min(8, len(c.token))])
 	}
 
-	res, err := c.hc.Do(req)
+	res, err := c.do(req)
 	if err != nil {
 		log.Printf("[ERROR] HTTP request failed: %v", err)
 		return nil, fmt.Errorf("HTTP request failed: %w", err)
@@ -183,20 +1090,18 @@ func (c *APIClient) UpsertSecret(p SecretPayload) (*SecretResponse, error) {
 	log.Printf("[DEBUG] Response status: %d", res.StatusCode)
 	log.Printf("[DEBUG] Response headers: %v", utils.RedactHTTPHeaders(res.Header))
 
-	b, readErr := io.ReadAll(res.Body)
+	b, readErr := c.readBody(res)
 	if readErr != nil {
 		log.Printf("[ERROR] Failed to read response body: %v", readErr)
 	} else {
 		safeRespBody := utils.RedactBytesChain(b)
-		log.Printf("[DEBUG] Response body: %s", string(safeRespBody))
+		log.Printf("[DEBUG] Response body: %s", utils.LogPreview(safeRespBody))
 	}
 
 	if res.StatusCode >= 300 {
+		log.Printf("[ERROR] Upsert secret failed (%s)", statusDesc(res))
 		if readErr != nil {
-			return nil, fmt.Errorf("upsert secret failed (status %d): unable to read response body: %w", res.StatusCode, readErr)
-		}
-		if len(b) == 0 {
-			return nil, fmt.Errorf("upsert secret failed (status %d): empty response body", res.StatusCode)
+			return nil, fmt.Errorf("upsert secret failed (%s): %w", statusDesc(res), readErr)
 		}
 
 		// Special handling for 401
@@ -206,11 +1111,11 @@ func (c *APIClient) UpsertSecret(p SecretPayload) (*SecretResponse, error) {
 			log.Printf("[DEBUG] API Version: %s", c.apiVersion)
 		}
 
-		return nil, fmt.Errorf("upsert secret failed (status %d): %s", res.StatusCode, string(b))
+		return nil, newAPIError("upsert secret", res, b)
 	}
 
 	if readErr != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", readErr)
+		return nil, readErr
 	}
 
 	// Return a success response
@@ -222,15 +1127,60 @@ func (c *APIClient) UpsertSecret(p SecretPayload) (*SecretResponse, error) {
 		UpdatedAt: time.Now().Format(time.RFC3339),
 	}
 
+	// Prefer the server's view of the object when the PUT response carries it;
+	// only fall back to the fabricated metadata above for empty bodies or missing fields.
+	if len(bytes.TrimSpace(b)) > 0 {
+		var parsed upsertResponse
+		if err := json.Unmarshal(b, &parsed); err != nil {
+			log.Printf("[WARN] Failed to decode upsert response, using local metadata: %v", err)
+		} else {
+			c.checkUnknownFields(ctx, "upsert secret", b, &parsed)
+			if parsed.Version > 0 {
+				out.Version = parsed.Version
+			}
+			if ts := normalizeTimestamp(parsed.UpdatedAt); ts != "" {
+				out.UpdatedAt = ts
+			}
+			if parsed.Tags != nil {
+				out.Tags = parsed.Tags
+			}
+			if parsed.Labels != nil {
+				out.Labels = parsed.Labels
+			}
+			if v, ok := parsed.Configs[p.Key]; ok && p.Generate {
+				out.Value = fmt.Sprintf("%v", v)
+			}
+		}
+	}
+	if p.Generate && out.Value == "" {
+		return nil, fmt.Errorf("upsert secret: the server did not return a generated value for %s", p.Key)
+	}
+
 	log.Printf("[DEBUG] Successfully upserted secret")
 	return out, nil
 }
 
-func (c *APIClient) DeleteSecret(ns, key string) error {
+func (c *APIClient) DeleteSecret(ctx context.Context, ns, key string) error {
+	ns, err := c.resolveNamespace(ns)
+	if err != nil {
+		return err
+	}
+	if err := c.checkProtected(ctx, ns, key); err != nil {
+		return err
+	}
+	defer c.nsCache.invalidate(ns)
+	if c.protocol == protocolGraphQL {
+		return c.graphqlDeleteSecret(ctx, ns, key)
+	}
+	ctx, cancel := c.withTimeout(ctx, "delete")
+	defer cancel()
+
+	key = c.fullKey(key)
+
 	// To delete a specific key, we need to update the namespace without that key
 	// Or use the appropriate Yggdrasil API endpoint
-	url := fmt.Sprintf("%s/%s/configurations/%s", c.baseURL, c.apiVersion, ns)
-	safeURL := utils.RedactURLQuery(url)
+	url := fmt.Sprintf("%s/%s/configurations/%s", c.baseURL, c.apiVersion, escapeNamespace(ns))
+	safeURL := c.safeURL(url)
 	log.Printf("[DEBUG] PUT (delete) request to: %s", safeURL)
 
 	// Send an empty value or use DELETE endpoint if available
@@ -241,13 +1191,12 @@ func (c *APIClient) DeleteSecret(ns, key string) error {
 	}
 
 	body, _ := json.Marshal(payload)
-	req, _ := http.NewRequest("PUT", url, bytes.NewReader(body))
-	req.Header.Set("token", c.token)
-	req.Header.Set("Content-Type", "application/json")
+	req, _ := http.NewRequestWithContext(ctx, "PUT", url, bytes.NewReader(body))
+	c.setHeaders(req, true)
 
 	log.Printf("[DEBUG] Request headers: %v", utils.RedactHTTPHeaders(req.Header))
 
-	res, err := c.hc.Do(req)
+	res, err := c.do(req)
 	if err != nil {
 		log.Printf("[ERROR] HTTP request failed: %v", err)
 		return fmt.Errorf("HTTP request failed: %w", err)
@@ -260,14 +1209,309 @@ func (c *APIClient) DeleteSecret(ns, key string) error {
 		return nil
 	}
 	if res.StatusCode >= 300 {
-		b, _ := io.ReadAll(res.Body)
+		b, _ := c.readBody(res)
 		safeBody := utils.RedactBytesChain(b)
-		log.Printf("[ERROR] Delete secret failed (status %d): %s", res.StatusCode, string(safeBody))
-		return fmt.Errorf("delete secret failed (status %d): %s", res.StatusCode, string(b))
+		log.Printf("[ERROR] Delete secret failed (%s): %s", statusDesc(res), utils.LogPreview(safeBody))
+		return newAPIError("delete secret", res, b)
 	}
 	return nil
 }
 
+// TransactionOp is a single upsert or delete inside a transaction.
+type TransactionOp struct {
+	Action    string `json:"action"` // "upsert" or "delete"
+	Namespace string `json:"namespace"`
+	Key       string `json:"key"`
+	Value     string `json:"value,omitempty"`
+}
+
+// errCommitUnknown is returned by CommitTransaction when the request failed
+// after it was sent, so the server may or may not have committed it.
+var errCommitUnknown = errors.New("the transaction may or may not have been committed")
+
+// CommitTransaction submits ops to the transaction endpoint so they are applied
+// atomically. It reports supported=false when the server has no such endpoint,
+// in which case nothing was applied. The request is never retried; when its
+// outcome cannot be known (a transport error, a timeout or a 5xx), the error
+// wraps errCommitUnknown.
+func (c *APIClient) CommitTransaction(ctx context.Context, ops []TransactionOp) (bool, error) {
+	if c.protocol == protocolGraphQL {
+		// The transaction endpoint is REST-only; callers fall back to
+		// sequential writes, which go through GraphQL.
+		return false, nil
+	}
+	ctx, cancel := c.withTimeout(ctx, "transaction")
+	defer cancel()
+
+	prefixed := make([]TransactionOp, len(ops))
+	for i, op := range ops {
+		ns, err := c.resolveNamespace(op.Namespace)
+		if err != nil {
+			return true, fmt.Errorf("operations[%d]: %w", i, err)
+		}
+		if op.Action == "delete" {
+			if err := c.checkProtected(ctx, ns, op.Key); err != nil {
+				return true, fmt.Errorf("operations[%d]: %w", i, err)
+			}
+		}
+		op.Namespace = ns
+		op.Key = c.fullKey(op.Key)
+		prefixed[i] = op
+		defer c.nsCache.invalidate(ns)
+	}
+	ops = prefixed
+
+	// POST /v2/transactions
+	url := fmt.Sprintf("%s/%s/transactions", c.baseURL, c.apiVersion)
+	log.Printf("[DEBUG] POST request to: %s", c.safeURL(url))
+
+	body, _ := json.Marshal(map[string]interface{}{"operations": ops})
+	log.Printf("[DEBUG] Request body: %s", utils.LogPreview(utils.RedactBytesChain(body)))
+
+	req, _ := http.NewRequestWithContext(withoutRetries(ctx), "POST", url, bytes.NewReader(body))
+	c.setHeaders(req, true)
+
+	res, err := c.do(req)
+	if err != nil {
+		log.Printf("[ERROR] HTTP request failed: %v", err)
+		return true, fmt.Errorf("%w: HTTP request failed: %w", errCommitUnknown, err)
+	}
+	defer res.Body.Close()
+
+	log.Printf("[DEBUG] Response status: %d", res.StatusCode)
+
+	switch {
+	case res.StatusCode == 404 || res.StatusCode == 405 || res.StatusCode == 501:
+		log.Printf("[DEBUG] Transaction endpoint not available (%s)", statusDesc(res))
+		return false, nil
+	case res.StatusCode >= 300:
+		b, _ := c.readBody(res)
+		log.Printf("[ERROR] Transaction failed (%s): %s", statusDesc(res), utils.LogPreview(utils.RedactBytesChain(b)))
+		if res.StatusCode >= 500 {
+			return true, fmt.Errorf("%w: %w", errCommitUnknown, newAPIError("transaction", res, b))
+		}
+		return true, newAPIError("transaction", res, b)
+	}
+	return true, nil
+}
+
+// doJSON sends in (when non-nil) as the JSON body of a request to url and
+// decodes a 2xx response into out (when non-nil). A 404 is reported as
+// found=false with no error. op names the operation in logs and errors.
+func (c *APIClient) doJSON(ctx context.Context, op, method, url string, in, out interface{}) (bool, error) {
+	if err := c.restOnly(op); err != nil {
+		return false, err
+	}
+	ctx, cancel := c.withTimeout(ctx, op)
+	defer cancel()
+
+	log.Printf("[DEBUG] %s request to: %s", method, c.safeURL(url))
+
+	var body io.Reader
+	if in != nil {
+		b, err := json.Marshal(in)
+		if err != nil {
+			return false, fmt.Errorf("%s: encoding request: %w", op, err)
+		}
+		log.Printf("[DEBUG] Request body: %s", utils.LogPreview(utils.RedactBytesChain(b)))
+		body = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+	c.setHeaders(req, in != nil)
+	log.Printf("[DEBUG] Request headers: %v", utils.RedactHTTPHeaders(req.Header))
+
+	res, err := c.do(req)
+	if err != nil {
+		log.Printf("[ERROR] HTTP request failed: %v", err)
+		return false, fmt.Errorf("HTTP request failed: %w", err)
+	}
+	defer res.Body.Close()
+
+	log.Printf("[DEBUG] Response status: %d", res.StatusCode)
+
+	b, err := c.readBody(res)
+	if err != nil {
+		return false, err
+	}
+	if res.StatusCode == 404 {
+		return false, nil
+	}
+	if res.StatusCode >= 300 {
+		log.Printf("[ERROR] %s failed (%s): %s", op, statusDesc(res), utils.LogPreview(utils.RedactBytesChain(b)))
+		return true, newAPIError(op, res, b)
+	}
+	log.Printf("[DEBUG] Response body: %s", utils.LogPreview(utils.RedactBytesChain(b)))
+
+	if out != nil && len(bytes.TrimSpace(b)) > 0 {
+		if err := json.Unmarshal(b, out); err != nil {
+			return true, fmt.Errorf("%s: failed to decode response: %w", op, err)
+		}
+		c.checkUnknownFields(ctx, op, b, out)
+	}
+	return true, nil
+}
+
+// readBody reads res.Body, failing rather than buffering more than
+// max_response_bytes from a misbehaving server.
+func (c *APIClient) readBody(res *http.Response) ([]byte, error) {
+	b, err := io.ReadAll(io.LimitReader(res.Body, c.maxResponseBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	if int64(len(b)) > c.maxResponseBytes {
+		return nil, fmt.Errorf("response body exceeds max_response_bytes (%d bytes)", c.maxResponseBytes)
+	}
+	return b, nil
+}
+
+// requestIDHeaders are checked in order for a server-assigned request ID.
+var requestIDHeaders = []string{"X-Request-Id", "X-Correlation-Id", "X-Amzn-Requestid", "X-Trace-Id"}
+
+func requestID(h http.Header) string {
+	for _, name := range requestIDHeaders {
+		if id := h.Get(name); id != "" {
+			return id
+		}
+	}
+	return ""
+}
+
+// statusDesc describes a response status for errors and logs, including the
+// server's request ID when present so a failure can be found in server logs.
+func statusDesc(res *http.Response) string {
+	if id := requestID(res.Header); id != "" {
+		return fmt.Sprintf("status %d, request_id=%s", res.StatusCode, id)
+	}
+	return fmt.Sprintf("status %d", res.StatusCode)
+}
+
+// idempotentMethods may be retried by RawRequest: sending them twice has the
+// same effect as sending them once.
+var idempotentMethods = map[string]bool{"GET": true, "HEAD": true, "PUT": true, "DELETE": true}
+
+// RawRequest issues an arbitrary request against the API using the client's
+// auth, TLS and retry settings; only idempotentMethods are retried.
+// apiPath is appended to the endpoint as-is.
+// Non-2xx statuses are returned as errors together with the response body.
+// With allowed_namespaces or denied_namespaces set, only paths whose
+// namespace can be checked are sent (see rawRequestNamespace).
+func (c *APIClient) RawRequest(ctx context.Context, method, apiPath string, body []byte) (int, []byte, error) {
+	if err := c.restOnly("raw request"); err != nil {
+		return 0, nil, err
+	}
+	if len(c.allowedNamespaces) > 0 || len(c.deniedNamespaces) > 0 {
+		ns, err := c.rawRequestNamespace(apiPath)
+		if err != nil {
+			return 0, nil, err
+		}
+		if err := c.checkNamespace(ns); err != nil {
+			return 0, nil, err
+		}
+	}
+	ctx, cancel := c.withTimeout(ctx, "raw request")
+	defer cancel()
+	if !idempotentMethods[method] {
+		// A POST that got a 502 may still have been applied.
+		ctx = withoutRetries(ctx)
+	}
+	if method != "GET" && method != "HEAD" {
+		// Any namespace may have been changed.
+		defer c.nsCache.invalidate("")
+	}
+
+	url := c.baseURL + "/" + strings.TrimPrefix(apiPath, "/")
+	safeURL := c.safeURL(url)
+	log.Printf("[DEBUG] %s request to: %s", method, safeURL)
+
+	var reqBody io.Reader
+	if body != nil {
+		reqBody = bytes.NewReader(body)
+		log.Printf("[DEBUG] Request body: %s", utils.LogPreview(utils.RedactBytesChain(body)))
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, reqBody)
+	if err != nil {
+		return 0, nil, fmt.Errorf("invalid request: %w", err)
+	}
+	c.setHeaders(req, body != nil)
+
+	log.Printf("[DEBUG] Request headers: %v", utils.RedactHTTPHeaders(req.Header))
+
+	res, err := c.do(req)
+	if err != nil {
+		log.Printf("[ERROR] HTTP request failed: %v", err)
+		return 0, nil, fmt.Errorf("HTTP request failed: %w", err)
+	}
+	defer res.Body.Close()
+
+	log.Printf("[DEBUG] Response status: %d", res.StatusCode)
+
+	b, err := c.readBody(res)
+	if err != nil {
+		return res.StatusCode, nil, err
+	}
+	log.Printf("[DEBUG] Response body: %s", utils.LogPreview(utils.RedactBytesChain(b)))
+
+	if res.StatusCode >= 300 {
+		return res.StatusCode, b, newAPIError(method+" "+apiPath, res, b)
+	}
+	return res.StatusCode, b, nil
+}
+
+// rawRequestNamespace returns the namespace that apiPath addresses, for the
+// paths whose layout is known: /<version>/namespaces/<namespace> and
+// /<version>/configurations/<namespace>[/...], where the namespace ends at the
+// first "latest", "at" or "deleted" segment, or at a version number followed
+// by "all". Any other path is an error, as its namespace cannot be checked.
+func (c *APIClient) rawRequestNamespace(apiPath string) (string, error) {
+	u, err := url.Parse(apiPath)
+	if err != nil {
+		return "", fmt.Errorf("invalid path %q: %w", apiPath, err)
+	}
+	var segs []string
+	for _, s := range strings.Split(u.EscapedPath(), "/") {
+		s, err := url.PathUnescape(s)
+		if err != nil {
+			return "", fmt.Errorf("invalid path %q: %w", apiPath, err)
+		}
+		switch s {
+		case "":
+			continue
+		case ".", "..":
+			return "", fmt.Errorf("path %q must not contain %q segments while allowed_namespaces or denied_namespaces is set", apiPath, s)
+		}
+		segs = append(segs, s)
+	}
+
+	unchecked := fmt.Errorf("the namespace of %q cannot be checked against allowed_namespaces and denied_namespaces; "+
+		"while either is set, api_request only accepts /%s/configurations/<namespace>/... and /%s/namespaces/<namespace> paths",
+		apiPath, c.apiVersion, c.apiVersion)
+	if len(segs) < 3 || segs[0] != c.apiVersion {
+		return "", unchecked
+	}
+	rest := segs[2:]
+	switch segs[1] {
+	case "namespaces":
+	case "configurations":
+		for i, s := range rest {
+			_, numErr := strconv.Atoi(s)
+			if s == "latest" || s == "at" || s == "deleted" || (numErr == nil && i == len(rest)-2 && rest[i+1] == "all") {
+				rest = rest[:i]
+				break
+			}
+		}
+	default:
+		return "", unchecked
+	}
+	ns := strings.Join(rest, "/")
+	if ns == "" {
+		return "", unchecked
+	}
+	return ns, nil
+}
+
 func min(a, b int) int {
 	if a < b {
 		return a
@@ -0,0 +1,65 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// SecretAlias points namespace/key at another secret.
+type SecretAlias struct {
+	Namespace       string `json:"-"`
+	Key             string `json:"-"`
+	TargetNamespace string `json:"target_namespace"`
+	TargetKey       string `json:"target_key"`
+}
+
+func (c *APIClient) aliasURL(ns, key string) string {
+	// /v2/aliases/:namespace/:key
+	return fmt.Sprintf("%s/%s/aliases/%s/%s", c.baseURL, c.apiVersion, escapeNamespace(ns), url.PathEscape(c.fullKey(key)))
+}
+
+// GetAlias returns the alias at ns/key, or nil if it does not exist.
+func (c *APIClient) GetAlias(ctx context.Context, ns, key string) (*SecretAlias, error) {
+	ns, err := c.resolveNamespace(ns)
+	if err != nil {
+		return nil, err
+	}
+	var out SecretAlias
+	found, err := c.doJSON(ctx, "get alias", "GET", c.aliasURL(ns, key), nil, &out)
+	if err != nil || !found {
+		return nil, err
+	}
+	out.Namespace = ns
+	out.Key = key
+	out.TargetKey = strings.TrimPrefix(out.TargetKey, c.keyPrefix)
+	return &out, nil
+}
+
+// PutAlias creates or repoints an alias.
+func (c *APIClient) PutAlias(ctx context.Context, a SecretAlias) error {
+	var err error
+	if a.Namespace, err = c.resolveNamespace(a.Namespace); err != nil {
+		return err
+	}
+	if a.TargetNamespace, err = c.resolveNamespace(a.TargetNamespace); err != nil {
+		return err
+	}
+	a.TargetKey = c.fullKey(a.TargetKey)
+	// Aliases change what resolve_refs reads return in any namespace.
+	defer c.nsCache.invalidate("")
+	_, err = c.doJSON(ctx, "put alias", "PUT", c.aliasURL(a.Namespace, a.Key), a, nil)
+	return err
+}
+
+// DeleteAlias removes an alias. A missing alias is not an error.
+func (c *APIClient) DeleteAlias(ctx context.Context, ns, key string) error {
+	ns, err := c.resolveNamespace(ns)
+	if err != nil {
+		return err
+	}
+	defer c.nsCache.invalidate("")
+	_, err = c.doJSON(ctx, "delete alias", "DELETE", c.aliasURL(ns, key), nil, nil)
+	return err
+}
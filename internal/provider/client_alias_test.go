@@ -0,0 +1,10 @@
+package provider
+
+import "testing"
+
+func TestAliasURLEscapesTheKey(t *testing.T) {
+	c := newTestClient(t, "https://ygg.example", Config{})
+	if got, want := c.aliasURL("team/prod", "a b?c"), "https://ygg.example/v2/aliases/team/prod/a%20b%3Fc"; got != want {
+		t.Errorf("aliasURL = %q, want %q", got, want)
+	}
+}
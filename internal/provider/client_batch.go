@@ -0,0 +1,179 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/m34l/terraform-provider-yggdrasil/internal/utils"
+)
+
+// batchRequest writes several keys of one namespace in a single PUT. A nil
+// value deletes the key, as in DeleteSecret.
+type batchRequest struct {
+	Configs map[string]*string `json:"configs"`
+}
+
+// ListSecrets returns every key/value of ns under the configured key_prefix,
+// with the prefix stripped, or nil if the namespace does not exist.
+func (c *APIClient) ListSecrets(ctx context.Context, ns string) (map[string]string, error) {
+	read, err := c.readNamespace(ctx, ns, readOptions{})
+	if err != nil || read == nil {
+		return nil, err
+	}
+	out := make(map[string]string, len(read.configs))
+	for k, v := range read.configs {
+		if !strings.HasPrefix(k, c.keyPrefix) {
+			continue
+		}
+		out[strings.TrimPrefix(k, c.keyPrefix)] = fmt.Sprintf("%v", v)
+	}
+	return out, nil
+}
+
+// Namespace is the key names of a namespace and the body they were read from.
+type Namespace struct {
+	Name    string
+	Keys    []string // sorted, key_prefix stripped
+	RawJSON []byte   // response body exactly as the server sent it
+}
+
+// GetNamespace reads ns, or returns nil if it does not exist.
+func (c *APIClient) GetNamespace(ctx context.Context, ns string) (*Namespace, error) {
+	read, err := c.readNamespace(ctx, ns, readOptions{keepRaw: true})
+	if err != nil || read == nil {
+		return nil, err
+	}
+	out := &Namespace{Name: normalizeNamespace(ns), Keys: []string{}, RawJSON: read.raw}
+	for k := range read.configs {
+		if strings.HasPrefix(k, c.keyPrefix) {
+			out.Keys = append(out.Keys, strings.TrimPrefix(k, c.keyPrefix))
+		}
+	}
+	sort.Strings(out.Keys)
+	return out, nil
+}
+
+// batchResponse is the optional per-key report of a batch write, e.g.
+// {"results": [{"key": "a", "status": 200}, {"key": "b", "status": 422, "error": "..."}]}.
+type batchResponse struct {
+	Results []struct {
+		Key    string `json:"key"`
+		Status int    `json:"status"`
+		Error  string `json:"error"`
+	} `json:"results"`
+}
+
+// BatchError is returned by WriteSecrets when the server reports that only
+// some keys were written. Keys not in Failed were applied.
+type BatchError struct {
+	Namespace string
+	Failed    map[string]string // key (without key_prefix) -> reason
+}
+
+func (e *BatchError) Error() string {
+	keys := make([]string, 0, len(e.Failed))
+	for k := range e.Failed {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	lines := make([]string, 0, len(keys))
+	for _, k := range keys {
+		lines = append(lines, fmt.Sprintf("  %s: %s", k, e.Failed[k]))
+	}
+	return fmt.Sprintf("batch write to %s partially failed; %d key(s) were not written:\n%s", e.Namespace, len(keys), strings.Join(lines, "\n"))
+}
+
+// WriteSecrets upserts values and deletes the keys in deletes in one request.
+// If the server reports per-key results and some keys failed, the error is a
+// *BatchError and all other keys were applied.
+func (c *APIClient) WriteSecrets(ctx context.Context, ns string, values map[string]string, deletes []string) error {
+	if err := c.restOnly("batch write"); err != nil {
+		return err
+	}
+	ns, err := c.resolveNamespace(ns)
+	if err != nil {
+		return err
+	}
+	if len(values) == 0 && len(deletes) == 0 {
+		return nil
+	}
+	for _, k := range deletes {
+		if err := c.checkProtected(ctx, ns, k); err != nil {
+			return err
+		}
+	}
+	defer c.nsCache.invalidate(ns)
+	ctx, cancel := c.withTimeout(ctx, "write")
+	defer cancel()
+
+	payload := batchRequest{Configs: make(map[string]*string, len(values)+len(deletes))}
+	for k, v := range values {
+		v := v
+		payload.Configs[c.fullKey(k)] = &v
+	}
+	for _, k := range deletes {
+		payload.Configs[c.fullKey(k)] = nil
+	}
+
+	// PUT /v2/configurations/:namespace
+	url := fmt.Sprintf("%s/%s/configurations/%s", c.baseURL, c.apiVersion, escapeNamespace(ns))
+	log.Printf("[DEBUG] PUT (batch) request to: %s (%d upserts, %d deletes)", c.safeURL(url), len(values), len(deletes))
+
+	body, _ := json.Marshal(payload)
+	req, _ := http.NewRequestWithContext(ctx, "PUT", url, bytes.NewReader(body))
+	c.setHeaders(req, true)
+
+	res, err := c.do(req)
+	if err != nil {
+		log.Printf("[ERROR] HTTP request failed: %v", err)
+		return fmt.Errorf("HTTP request failed: %w", err)
+	}
+	defer res.Body.Close()
+
+	log.Printf("[DEBUG] Response status: %d", res.StatusCode)
+
+	b, err := c.readBody(res)
+	if err != nil {
+		return err
+	}
+	log.Printf("[DEBUG] Response body: %s", utils.LogPreview(utils.RedactBytesChain(b)))
+
+	var parsed batchResponse
+	if json.Unmarshal(b, &parsed) != nil || len(parsed.Results) == 0 {
+		// No per-key report: the status decides for the whole batch.
+		switch {
+		case res.StatusCode == 404:
+			return fmt.Errorf("batch write failed: namespace %q does not exist", ns)
+		case res.StatusCode >= 300:
+			return newAPIError("batch write", res, b)
+		}
+		return nil
+	}
+	c.checkUnknownFields(ctx, "batch write", b, &parsed)
+
+	failed := map[string]string{}
+	for _, r := range parsed.Results {
+		if r.Status < 300 && r.Error == "" {
+			continue
+		}
+		reason := r.Error
+		if reason == "" {
+			reason = fmt.Sprintf("status %d", r.Status)
+		}
+		failed[strings.TrimPrefix(r.Key, c.keyPrefix)] = reason
+	}
+	if len(failed) > 0 {
+		log.Printf("[ERROR] Batch write to %s: %d of %d keys failed (%s)", ns, len(failed), len(payload.Configs), statusDesc(res))
+		return &BatchError{Namespace: ns, Failed: failed}
+	}
+	if res.StatusCode >= 300 {
+		return newAPIError("batch write", res, b)
+	}
+	return nil
+}
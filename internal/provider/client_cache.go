@@ -0,0 +1,82 @@
+package provider
+
+import (
+	"sync"
+	"time"
+)
+
+const defaultNamespaceCacheTTL = 5 * time.Second
+
+// namespaceCache keeps recent namespace reads for a short time so that the
+// many per-resource reads of a refresh share one fetch per namespace, not
+// only the reads that happen to run concurrently (see readNamespace). Writes
+// through the client invalidate the namespaces they touch.
+type namespaceCache struct {
+	ttl time.Duration
+
+	mu      sync.Mutex
+	entries map[string]cacheEntry // by flight key
+	gen     map[string]uint64     // by namespace; bumped on invalidation
+	allGen  uint64                // bumped when everything is invalidated
+}
+
+type cacheEntry struct {
+	ns      string
+	read    *namespaceRead
+	expires time.Time
+}
+
+func newNamespaceCache(ttl time.Duration) *namespaceCache {
+	return &namespaceCache{ttl: ttl, entries: map[string]cacheEntry{}, gen: map[string]uint64{}}
+}
+
+// generation identifies the cache state of ns; put ignores results fetched
+// under an older generation, i.e. started before a write to ns finished.
+func (nc *namespaceCache) generation(ns string) uint64 {
+	nc.mu.Lock()
+	defer nc.mu.Unlock()
+	return nc.gen[ns] + nc.allGen
+}
+
+func (nc *namespaceCache) get(key string) (*namespaceRead, bool) {
+	nc.mu.Lock()
+	defer nc.mu.Unlock()
+	e, ok := nc.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(e.expires) {
+		delete(nc.entries, key)
+		return nil, false
+	}
+	return e.read, true
+}
+
+func (nc *namespaceCache) put(ns, key string, gen uint64, read *namespaceRead) {
+	nc.mu.Lock()
+	defer nc.mu.Unlock()
+	if nc.gen[ns]+nc.allGen != gen {
+		return
+	}
+	nc.entries[key] = cacheEntry{ns: ns, read: read, expires: time.Now().Add(nc.ttl)}
+}
+
+// invalidate drops cached reads of ns, or of every namespace when ns is "".
+func (nc *namespaceCache) invalidate(ns string) {
+	if nc == nil {
+		return
+	}
+	nc.mu.Lock()
+	defer nc.mu.Unlock()
+	if ns == "" {
+		nc.allGen++
+		nc.entries = map[string]cacheEntry{}
+		return
+	}
+	nc.gen[ns]++
+	for k, e := range nc.entries {
+		if e.ns == ns {
+			delete(nc.entries, k)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+func TestRefreshOfManySecretsFetchesNamespaceOnce(t *testing.T) {
+	const n = 25
+	srv := newFakeServer(t)
+	r := &SecretResource{client: newTestClient(t, srv.URL, Config{})}
+	s := resourceSchema(t, r)
+
+	states := make([]tftypes.Value, n)
+	for i := range states {
+		states[i] = testCreate(t, r, s, tfObject(t, s, map[string]tftypes.Value{
+			"namespace": tfString("team"),
+			"key":       tfString(fmt.Sprintf("key_%02d", i)),
+			"value":     tfString(fmt.Sprintf("value %d", i)),
+		}))
+	}
+	before := srv.count(http.MethodGet, "/latest/all")
+	for _, st := range states {
+		testRead(t, r, s, st)
+	}
+	if got := srv.count(http.MethodGet, "/latest/all") - before; got != 1 {
+		t.Errorf("refreshing %d secrets fetched the namespace %d times, want 1", n, got)
+	}
+}
+
+func BenchmarkRefreshSecretsInOneNamespace(b *testing.B) {
+	var fetches atomic.Int64
+	configs := `{`
+	for i := 0; i < 100; i++ {
+		if i > 0 {
+			configs += `,`
+		}
+		configs += fmt.Sprintf(`"key_%03d":"value %d"`, i, i)
+	}
+	configs += `}`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		fetches.Add(1)
+		_, _ = w.Write([]byte(configs))
+	}))
+	defer srv.Close()
+	c, err := newClient(Config{Endpoint: srv.URL, Token: "test-token-0123456789"})
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	ctx := context.Background()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c.nsCache.invalidate("")
+		fetches.Store(0)
+		for k := 0; k < 100; k++ {
+			if _, err := c.GetSecret(ctx, "team", fmt.Sprintf("key_%03d", k)); err != nil {
+				b.Fatal(err)
+			}
+		}
+		if got := fetches.Load(); got != 1 {
+			b.Fatalf("100 reads fetched the namespace %d times, want 1", got)
+		}
+	}
+}
+
+func TestGetSecretMinVersionBypassesCache(t *testing.T) {
+	var version atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		// Every fetch observes a newer namespace version, as a replica catching up would.
+		w.Header().Set("X-Config-Version", strconv.FormatInt(version.Add(1), 10))
+		_, _ = w.Write([]byte(`{"db_password":"s3cret"}`))
+	}))
+	defer srv.Close()
+	c := newTestClient(t, srv.URL, Config{})
+	ctx := context.Background()
+
+	if _, err := c.GetSecret(ctx, "team", "db_password"); err != nil {
+		t.Fatal(err)
+	}
+	// Version 1 is now cached for the cache TTL, which outlasts the wait.
+	start := time.Now()
+	out, err := c.GetSecretMinVersion(ctx, "team", "db_password", 2, time.Second)
+	if err != nil {
+		t.Fatalf("GetSecretMinVersion: %v", err)
+	}
+	if out.Version != 2 {
+		t.Errorf("version = %d, want 2", out.Version)
+	}
+	if d := time.Since(start); d >= minVersionPollInterval {
+		t.Errorf("took %s; the first poll should have seen version 2", d)
+	}
+}
+
+func TestSharedReadSurvivesCancellationOfItsLeader(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var fetches atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if fetches.Add(1) == 1 {
+			close(started)
+		}
+		<-release
+		_, _ = w.Write([]byte(`{"db_password":"s3cret"}`))
+	}))
+	defer srv.Close()
+	c := newTestClient(t, srv.URL, Config{})
+
+	leaderCtx, cancel := context.WithCancel(context.Background())
+	leaderErr := make(chan error, 1)
+	go func() {
+		_, err := c.GetSecret(leaderCtx, "team", "db_password")
+		leaderErr <- err
+	}()
+	<-started
+
+	follower := make(chan *SecretResponse, 1)
+	go func() {
+		out, err := c.GetSecret(context.Background(), "team", "db_password")
+		if err != nil {
+			t.Errorf("follower: %v", err)
+		}
+		follower <- out
+	}()
+	time.Sleep(50 * time.Millisecond) // let the follower join the in-flight read
+	cancel()
+	if err := <-leaderErr; err == nil {
+		t.Error("cancelled leader got no error")
+	}
+	close(release)
+
+	if out := <-follower; out == nil || out.Value != "s3cret" {
+		t.Errorf("follower got %+v, want the shared read's result", out)
+	}
+	if n := fetches.Load(); n != 1 {
+		t.Errorf("namespace was fetched %d times, want 1", n)
+	}
+}
@@ -0,0 +1,207 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/m34l/terraform-provider-yggdrasil/internal/utils"
+)
+
+const (
+	protocolREST    = "rest"
+	protocolGraphQL = "graphql"
+)
+
+const (
+	gqlGetSecret = `query GetConfiguration($namespace: String!, $key: String!, $version: String) {
+  configuration(namespace: $namespace, key: $key, version: $version) { value version updatedAt }
+}`
+	gqlUpsertSecret = `mutation UpsertConfiguration($namespace: String!, $key: String!, $value: String!, $tags: JSON, $labels: JSON) {
+  upsertConfiguration(namespace: $namespace, key: $key, value: $value, tags: $tags, labels: $labels) { version updatedAt tags labels }
+}`
+	gqlGetSecretTags = `query GetConfigurationTags($namespace: String!, $key: String!) {
+  configuration(namespace: $namespace, key: $key) { tags }
+}`
+	gqlDeleteSecret = `mutation DeleteConfiguration($namespace: String!, $key: String!) {
+  deleteConfiguration(namespace: $namespace, key: $key) { deleted }
+}`
+)
+
+// gqlConfiguration is the configuration object returned by queries and mutations.
+type gqlConfiguration struct {
+	Value     *string           `json:"value"`
+	Version   int               `json:"version"`
+	UpdatedAt json.RawMessage   `json:"updatedAt"`
+	Tags      map[string]string `json:"tags"`
+	Labels    map[string]string `json:"labels"`
+}
+
+// graphql posts query to graphql_endpoint and decodes its data into out. Auth,
+// TLS, retries and timeouts are the same as for REST requests. GraphQL errors
+// in a 200 response are returned as an error.
+func (c *APIClient) graphql(ctx context.Context, op, query string, vars map[string]interface{}, out interface{}) error {
+	ctx, cancel := c.withTimeout(ctx, op)
+	defer cancel()
+
+	log.Printf("[DEBUG] GraphQL %s request to: %s", op, c.safeURL(c.graphqlEndpoint))
+
+	body, err := json.Marshal(map[string]interface{}{"query": query, "variables": vars})
+	if err != nil {
+		return fmt.Errorf("%s: encoding request: %w", op, err)
+	}
+	log.Printf("[DEBUG] Request body: %s", utils.LogPreview(utils.RedactBytesChain(body)))
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.graphqlEndpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	c.setHeaders(req, true)
+	// GraphQL servers only speak JSON regardless of content_type.
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := c.do(req)
+	if err != nil {
+		log.Printf("[ERROR] HTTP request failed: %v", err)
+		return fmt.Errorf("HTTP request failed: %w", err)
+	}
+	defer res.Body.Close()
+
+	log.Printf("[DEBUG] Response status: %d", res.StatusCode)
+
+	b, err := c.readBody(res)
+	if err != nil {
+		return err
+	}
+	log.Printf("[DEBUG] Response body: %s", utils.LogPreview(utils.RedactBytesChain(b)))
+	if res.StatusCode >= 300 {
+		return newAPIError(op, res, b)
+	}
+
+	var parsed struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(b, &parsed); err != nil {
+		return fmt.Errorf("%s: failed to decode response: %w", op, err)
+	}
+	if len(parsed.Errors) > 0 {
+		msgs := make([]string, 0, len(parsed.Errors))
+		for _, e := range parsed.Errors {
+			msgs = append(msgs, e.Message)
+		}
+		return fmt.Errorf("%s failed (%s): %s", op, statusDesc(res), strings.Join(msgs, "; "))
+	}
+	if out != nil && len(parsed.Data) > 0 {
+		if err := json.Unmarshal(parsed.Data, out); err != nil {
+			return fmt.Errorf("%s: failed to decode data: %w", op, err)
+		}
+		c.checkUnknownFields(ctx, op, parsed.Data, out)
+	}
+	return nil
+}
+
+// restOnly returns an error for op when the client uses GraphQL, for
+// operations that only exist in the REST API. Failing up front keeps a 404
+// from a server that does not serve REST from reading as "not found", which
+// would drop resources from state.
+func (c *APIClient) restOnly(op string) error {
+	if c.protocol != protocolGraphQL {
+		return nil
+	}
+	return fmt.Errorf("%s is not supported with protocol = %q", op, protocolGraphQL)
+}
+
+func (c *APIClient) graphqlGetSecret(ctx context.Context, ns, key string, opts readOptions) (*SecretResponse, error) {
+	if opts.resolveRefs || opts.asOf != "" {
+		return nil, fmt.Errorf("resolve_refs and as_of are not supported with protocol = %q", protocolGraphQL)
+	}
+	ns, err := c.resolveNamespace(ns)
+	if err != nil {
+		return nil, err
+	}
+	key = c.fullKey(key)
+
+	vars := map[string]interface{}{"namespace": ns, "key": key}
+	if opts.ref != "" {
+		vars["version"] = opts.ref
+	}
+	var data struct {
+		Configuration *gqlConfiguration `json:"configuration"`
+	}
+	if err := c.graphql(ctx, "get secret", gqlGetSecret, vars, &data); err != nil {
+		return nil, err
+	}
+	if data.Configuration == nil || data.Configuration.Value == nil {
+		return nil, nil
+	}
+	out := &SecretResponse{
+		Namespace: ns,
+		Key:       key,
+		Value:     *data.Configuration.Value,
+		Version:   data.Configuration.Version,
+		UpdatedAt: normalizeTimestamp(data.Configuration.UpdatedAt),
+	}
+	if out.Version == 0 {
+		out.Version, _ = strconv.Atoi(opts.ref)
+	}
+	return out, nil
+}
+
+func (c *APIClient) graphqlUpsertSecret(ctx context.Context, p SecretPayload) (*SecretResponse, error) {
+	if p.Generate {
+		return nil, fmt.Errorf("generate is not supported with protocol = %q", protocolGraphQL)
+	}
+	p.Key = c.fullKey(p.Key)
+
+	vars := map[string]interface{}{"namespace": p.Namespace, "key": p.Key, "value": p.Value}
+	if len(p.Tags) > 0 {
+		vars["tags"] = p.Tags
+	}
+	if len(p.Labels) > 0 {
+		vars["labels"] = p.Labels
+	}
+	var data struct {
+		UpsertConfiguration *gqlConfiguration `json:"upsertConfiguration"`
+	}
+	if err := c.graphql(ctx, "upsert secret", gqlUpsertSecret, vars, &data); err != nil {
+		return nil, err
+	}
+
+	out := &SecretResponse{Namespace: p.Namespace, Key: p.Key, Value: p.Value, Version: 1}
+	if cfg := data.UpsertConfiguration; cfg != nil {
+		if cfg.Version > 0 {
+			out.Version = cfg.Version
+		}
+		out.UpdatedAt = normalizeTimestamp(cfg.UpdatedAt)
+		out.Tags = cfg.Tags
+		out.Labels = cfg.Labels
+	}
+	return out, nil
+}
+
+func (c *APIClient) graphqlGetSecretTags(ctx context.Context, ns, key string) (map[string]string, error) {
+	vars := map[string]interface{}{"namespace": ns, "key": c.fullKey(key)}
+	var data struct {
+		Configuration *gqlConfiguration `json:"configuration"`
+	}
+	if err := c.graphql(ctx, "get tags", gqlGetSecretTags, vars, &data); err != nil {
+		return nil, err
+	}
+	if data.Configuration == nil {
+		return nil, nil
+	}
+	return data.Configuration.Tags, nil
+}
+
+func (c *APIClient) graphqlDeleteSecret(ctx context.Context, ns, key string) error {
+	vars := map[string]interface{}{"namespace": ns, "key": c.fullKey(key)}
+	return c.graphql(ctx, "delete secret", gqlDeleteSecret, vars, nil)
+}
@@ -0,0 +1,91 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const (
+	// lockWait is how long AcquireLock keeps retrying while another holder has
+	// the lock before giving up.
+	lockWait         = 2 * time.Minute
+	lockPollInterval = 2 * time.Second
+	// lockTTL lets the server expire a lock whose holder crashed before
+	// releasing it.
+	lockTTL = 5 * time.Minute
+)
+
+// errSecretLocked is returned by AcquireLock when the lock is still held by
+// someone else after lockWait.
+var errSecretLocked = errors.New("secret is locked by another operation")
+
+type lockRequest struct {
+	TTLSeconds int `json:"ttl_seconds"`
+}
+
+type lockResponse struct {
+	LockID string `json:"lock_id"`
+}
+
+func (c *APIClient) lockURL(ns, key string) string {
+	// /v2/locks/:namespace/:key
+	return fmt.Sprintf("%s/%s/locks/%s/%s", c.baseURL, c.apiVersion, escapeNamespace(ns), url.PathEscape(c.fullKey(key)))
+}
+
+// AcquireLock takes the server's advisory lock on ns/key, polling while
+// another holder has it (409 or 423). The returned release func must be
+// called once the protected write is done, whether or not it succeeded.
+func (c *APIClient) AcquireLock(ctx context.Context, ns, key string) (func(), error) {
+	ns, err := c.resolveNamespace(ns)
+	if err != nil {
+		return nil, err
+	}
+	lockURL := c.lockURL(ns, key)
+	deadline := time.Now().Add(lockWait)
+	for {
+		var out lockResponse
+		// Not retried: if a response is lost, a repeated POST would conflict
+		// with the lock it already took.
+		found, err := c.doJSON(withoutRetries(ctx), "acquire lock", "POST", lockURL, lockRequest{TTLSeconds: int(lockTTL.Seconds())}, &out)
+		if err == nil && !found {
+			return nil, fmt.Errorf("acquire lock failed: the server has no lock endpoint (status 404)")
+		}
+		if err == nil {
+			log.Printf("[DEBUG] Acquired lock on %s/%s (lock_id=%s)", ns, key, out.LockID)
+			return func() { c.releaseLock(ctx, lockURL, ns, key, out.LockID) }, nil
+		}
+		if status := errorStatus(err); status != http.StatusConflict && status != http.StatusLocked {
+			return nil, err
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("%w: %s/%s still locked after %s: %v", errSecretLocked, ns, key, lockWait, err)
+		}
+		log.Printf("[DEBUG] %s/%s is locked, retrying in %s", ns, key, lockPollInterval)
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("%w: %s/%s: %v", errSecretLocked, ns, key, ctx.Err())
+		case <-time.After(lockPollInterval):
+		}
+	}
+}
+
+// releaseLock is best effort: a lock that cannot be released expires after
+// lockTTL, so failures are only logged.
+func (c *APIClient) releaseLock(ctx context.Context, lockURL, ns, key, lockID string) {
+	// Release even when the operation itself was cancelled or timed out;
+	// doJSON bounds the release by request_timeout.
+	ctx = detachedHeaders(ctx)
+	if lockID != "" {
+		lockURL += "?lock_id=" + url.QueryEscape(lockID)
+	}
+	if _, err := c.doJSON(ctx, "release lock", "DELETE", lockURL, nil, nil); err != nil {
+		log.Printf("[WARN] Failed to release lock on %s/%s, it expires after %s: %v", ns, key, lockTTL, err)
+		return
+	}
+	log.Printf("[DEBUG] Released lock on %s/%s", ns, key)
+}
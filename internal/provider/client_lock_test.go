@@ -0,0 +1,73 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLockIsReleasedAfterTheOperationTimedOut(t *testing.T) {
+	var mu sync.Mutex
+	var released []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodDelete {
+			mu.Lock()
+			released = append(released, r.Header.Get("X-Intent"))
+			mu.Unlock()
+		}
+		_, _ = w.Write([]byte(`{"lock_id":"l-1"}`))
+	}))
+	defer srv.Close()
+	c := newTestClient(t, srv.URL, Config{})
+
+	// As withOperationTimeout sets it up for a timeouts block.
+	deadline := time.Now().Add(100 * time.Millisecond)
+	ctx, cancel := context.WithDeadline(context.WithValue(context.Background(), operationTimeoutKey{}, deadline), deadline)
+	defer cancel()
+	ctx = withHeaders(ctx, map[string]string{"X-Intent": "rotate"})
+
+	release, err := c.AcquireLock(ctx, "team", "db_password")
+	if err != nil {
+		t.Fatalf("AcquireLock: %v", err)
+	}
+	<-ctx.Done()
+	release()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(released) != 1 || released[0] != "rotate" {
+		t.Errorf("release requests carried X-Intent %q, want one with %q", released, "rotate")
+	}
+}
+
+func TestAcquireLockIsNotRetried(t *testing.T) {
+	var mu sync.Mutex
+	posts := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		mu.Lock()
+		posts++
+		mu.Unlock()
+		http.Error(w, "upstream timed out", http.StatusBadGateway)
+	}))
+	defer srv.Close()
+	c := newTestClient(t, srv.URL, Config{})
+
+	if _, err := c.AcquireLock(context.Background(), "team", "db_password"); errorStatus(err) != http.StatusBadGateway {
+		t.Errorf("err = %v, want the 502", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if posts != 1 {
+		t.Errorf("lock was requested %d times, want once", posts)
+	}
+}
+
+func TestLockURLEscapesTheKey(t *testing.T) {
+	c := newTestClient(t, "https://ygg.example", Config{})
+	if got, want := c.lockURL("team/prod", "a b?c"), "https://ygg.example/v2/locks/team/prod/a%20b%3Fc"; got != want {
+		t.Errorf("lockURL = %q, want %q", got, want)
+	}
+}
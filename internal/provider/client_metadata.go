@@ -0,0 +1,165 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// NamespaceMetadata describes a namespace without its values.
+type NamespaceMetadata struct {
+	Namespace string
+	Version   int    // 0 when the server does not report one
+	UpdatedAt string // RFC3339, or "" when the server does not report one
+	ETag      string
+}
+
+// versionHeaders are checked in order for the namespace version.
+var versionHeaders = []string{"X-Config-Version", "X-Yggdrasil-Version"}
+
+// GetNamespaceMetadata returns the metadata of ns, or nil if it does not
+// exist. It sends a HEAD so no values are transferred, and falls back to a
+// full read when the server does not support HEAD on the namespace.
+func (c *APIClient) GetNamespaceMetadata(ctx context.Context, ns string) (*NamespaceMetadata, error) {
+	if err := c.restOnly("get namespace metadata"); err != nil {
+		return nil, err
+	}
+	ns, err := c.resolveNamespace(ns)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := c.withTimeout(ctx, "metadata")
+	defer cancel()
+
+	// HEAD /v2/configurations/:namespace/latest/all
+	url := fmt.Sprintf("%s/%s/configurations/%s/latest/all", c.readBaseURL, c.apiVersion, escapeNamespace(ns))
+	log.Printf("[DEBUG] HEAD request to: %s", c.safeURL(url))
+
+	req, _ := http.NewRequestWithContext(ctx, "HEAD", url, nil)
+	c.setHeaders(req, false)
+
+	res, err := c.doWith(c.readHC, req)
+	if err != nil {
+		log.Printf("[ERROR] HTTP request failed: %v", err)
+		return nil, fmt.Errorf("HTTP request failed: %w", err)
+	}
+	_, _ = io.Copy(io.Discard, res.Body)
+	res.Body.Close()
+
+	log.Printf("[DEBUG] Response status: %d", res.StatusCode)
+
+	switch {
+	case res.StatusCode == 404:
+		return nil, nil
+	case res.StatusCode == 405 || res.StatusCode == 501:
+		log.Printf("[DEBUG] HEAD not supported for namespaces (%s), falling back to a full read", statusDesc(res))
+		read, err := c.readNamespace(ctx, ns, readOptions{})
+		if err != nil || read == nil {
+			return nil, err
+		}
+		return metadataFromHeader(ns, read.header), nil
+	case res.StatusCode >= 300:
+		// HEAD responses carry no body to explain the failure.
+		return nil, newAPIError("get namespace metadata", res, nil)
+	}
+	return metadataFromHeader(ns, res.Header), nil
+}
+
+func metadataFromHeader(ns string, h http.Header) *NamespaceMetadata {
+	md := &NamespaceMetadata{Namespace: ns, ETag: h.Get("ETag")}
+	for _, name := range versionHeaders {
+		if v, err := strconv.Atoi(h.Get(name)); err == nil {
+			md.Version = v
+			break
+		}
+	}
+	if lm := h.Get("Last-Modified"); lm != "" {
+		if t, err := http.ParseTime(lm); err == nil {
+			md.UpdatedAt = t.UTC().Format(time.RFC3339)
+		}
+	}
+	return md
+}
+
+// GetSecretTags returns the tags currently stored on ns/key, including tags
+// set outside Terraform. A secret without tags, or one that does not exist,
+// yields nil.
+func (c *APIClient) GetSecretTags(ctx context.Context, ns, key string) (map[string]string, error) {
+	ns, err := c.resolveNamespace(ns)
+	if err != nil {
+		return nil, err
+	}
+	if c.protocol == protocolGraphQL {
+		return c.graphqlGetSecretTags(ctx, ns, key)
+	}
+	// GET /v2/configurations/:namespace/latest/:key/tags
+	tagsURL := fmt.Sprintf("%s/%s/configurations/%s/latest/%s/tags", c.baseURL, c.apiVersion, escapeNamespace(ns), url.PathEscape(c.fullKey(key)))
+	var out struct {
+		Tags map[string]string `json:"tags"`
+	}
+	if _, err := c.doJSON(ctx, "get tags", "GET", tagsURL, nil, &out); err != nil {
+		return nil, err
+	}
+	return out.Tags, nil
+}
+
+// protectedBy returns the tag in tags that matches protected_tag ("name" or
+// "name=value"), or "" when the secret is not protected.
+func (c *APIClient) protectedBy(tags map[string]string) string {
+	if c.protectedTag == "" {
+		return ""
+	}
+	name, value, hasValue := strings.Cut(c.protectedTag, "=")
+	v, ok := tags[name]
+	if !ok || (hasValue && v != value) {
+		return ""
+	}
+	return name + "=" + v
+}
+
+// protectedError reports a delete that was refused because the secret carries
+// protected_tag.
+type protectedError struct {
+	Namespace string
+	Key       string
+	Tag       string
+}
+
+func (e *protectedError) Error() string {
+	return fmt.Sprintf("%s/%s is tagged %s and was not deleted", e.Namespace, e.Key, e.Tag)
+}
+
+type forceDeleteKey struct{}
+
+// withForceDelete returns ctx with the protected_tag check of deletes turned
+// off, for force_delete and for rolling back keys written moments before.
+func withForceDelete(ctx context.Context) context.Context {
+	return context.WithValue(ctx, forceDeleteKey{}, true)
+}
+
+// checkProtected returns a *protectedError if ns/key is tagged with
+// protected_tag on the server. Every client method that deletes a key calls
+// it first, so a secret cannot be deleted through a path that forgot the
+// check, unless ctx comes from withForceDelete.
+func (c *APIClient) checkProtected(ctx context.Context, ns, key string) error {
+	if c.protectedTag == "" {
+		return nil
+	}
+	if force, _ := ctx.Value(forceDeleteKey{}).(bool); force {
+		return nil
+	}
+	tags, err := c.GetSecretTags(ctx, ns, key)
+	if err != nil {
+		return fmt.Errorf("checking protected_tag of %s/%s: %w", ns, key, err)
+	}
+	if tag := c.protectedBy(tags); tag != "" {
+		return &protectedError{Namespace: ns, Key: key, Tag: tag}
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package provider
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+// pinMismatchError reports a server whose leaf certificate key is not in
+// tls_pin_sha256.
+type pinMismatchError struct {
+	ServerName string
+	Got        string
+}
+
+func (e *pinMismatchError) Error() string {
+	return fmt.Sprintf("certificate pin mismatch for %s: leaf public key has SHA-256 %s, which is not in tls_pin_sha256", e.ServerName, e.Got)
+}
+
+// parsePin accepts a base64 SHA-256 fingerprint, optionally prefixed with
+// "sha256/" as printed by common pinning tools, and returns it normalized.
+func parsePin(pin string) (string, error) {
+	b, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(pin, "sha256/"))
+	if err != nil || len(b) != sha256.Size {
+		return "", fmt.Errorf("%q is not a base64-encoded SHA-256 fingerprint", pin)
+	}
+	return base64.StdEncoding.EncodeToString(b), nil
+}
+
+// verifyPins returns a tls.Config.VerifyConnection callback that accepts only
+// servers whose leaf certificate's SubjectPublicKeyInfo hashes to one of pins.
+// It runs after (not instead of) the usual chain verification.
+func verifyPins(pins []string) func(tls.ConnectionState) error {
+	allowed := make(map[string]bool, len(pins))
+	for _, p := range pins {
+		allowed[p] = true
+	}
+	return func(cs tls.ConnectionState) error {
+		if len(cs.PeerCertificates) == 0 {
+			return &pinMismatchError{ServerName: cs.ServerName, Got: "(no certificate)"}
+		}
+		sum := sha256.Sum256(cs.PeerCertificates[0].RawSubjectPublicKeyInfo)
+		got := base64.StdEncoding.EncodeToString(sum[:])
+		if !allowed[got] {
+			return &pinMismatchError{ServerName: cs.ServerName, Got: got}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,107 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// searchPageSize is the page size requested from the search endpoint.
+const searchPageSize = 100
+
+// SearchQuery selects secrets across namespaces. Empty fields match anything.
+type SearchQuery struct {
+	KeyGlob string            // glob on the key, without key_prefix
+	Tags    map[string]string // all must match
+}
+
+// SearchResult identifies a matching secret; values are never returned.
+type SearchResult struct {
+	Namespace string
+	Key       string
+	Version   int
+	UpdatedAt string // RFC3339, or "" when the server does not report one
+}
+
+type searchResponse struct {
+	Results []struct {
+		Namespace string          `json:"namespace"`
+		Key       string          `json:"key"`
+		Version   int             `json:"version"`
+		UpdatedAt json.RawMessage `json:"updated_at"`
+	} `json:"results"`
+	NextPageToken string `json:"next_page_token"`
+}
+
+// SearchSecrets returns every secret matching q, following pagination. Pages
+// are fetched one after another, each a separate request retried on
+// retry_status_codes like any other call; nothing paces them beyond that.
+// Results in namespaces the provider may not access, or outside key_prefix,
+// are dropped.
+func (c *APIClient) SearchSecrets(ctx context.Context, q SearchQuery) ([]SearchResult, error) {
+	params := url.Values{}
+	params.Set("page_size", strconv.Itoa(searchPageSize))
+	if q.KeyGlob != "" || c.keyPrefix != "" {
+		glob := q.KeyGlob
+		if glob == "" {
+			glob = "*"
+		}
+		params.Set("key", c.fullKey(glob))
+	}
+	tagNames := make([]string, 0, len(q.Tags))
+	for k := range q.Tags {
+		tagNames = append(tagNames, k)
+	}
+	sort.Strings(tagNames)
+	for _, k := range tagNames {
+		params.Add("tag", k+"="+q.Tags[k])
+	}
+
+	out := []SearchResult{}
+	seen := map[string]bool{}
+	for page := 1; ; page++ {
+		// GET /v2/search?key=...&tag=name=value&page_token=...
+		reqURL := fmt.Sprintf("%s/%s/search?%s", c.readBaseURL, c.apiVersion, params.Encode())
+		var res searchResponse
+		found, err := c.doJSON(ctx, "search", "GET", reqURL, nil, &res)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			return nil, fmt.Errorf("search failed: the server has no search endpoint (status 404)")
+		}
+		log.Printf("[DEBUG] Search page %d returned %d results", page, len(res.Results))
+		for _, r := range res.Results {
+			ns := normalizeNamespace(r.Namespace)
+			if c.checkNamespace(ns) != nil || !strings.HasPrefix(r.Key, c.keyPrefix) {
+				continue
+			}
+			out = append(out, SearchResult{
+				Namespace: ns,
+				Key:       strings.TrimPrefix(r.Key, c.keyPrefix),
+				Version:   r.Version,
+				UpdatedAt: normalizeTimestamp(r.UpdatedAt),
+			})
+		}
+		if res.NextPageToken == "" {
+			break
+		}
+		if seen[res.NextPageToken] {
+			return nil, fmt.Errorf("search failed: the server returned page token %q twice", res.NextPageToken)
+		}
+		seen[res.NextPageToken] = true
+		params.Set("page_token", res.NextPageToken)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Namespace != out[j].Namespace {
+			return out[i].Namespace < out[j].Namespace
+		}
+		return out[i].Key < out[j].Key
+	})
+	return out, nil
+}
@@ -0,0 +1,63 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+)
+
+// DeletedSecret describes a soft-deleted secret that the server can still
+// recover.
+type DeletedSecret struct {
+	Namespace        string `json:"-"`
+	Key              string `json:"-"`
+	DeletedAt        string `json:"deleted_at"`
+	RecoverableUntil string `json:"recoverable_until"`
+}
+
+// SoftDeleteSecret marks ns/key deleted while the server keeps it recoverable
+// for its recovery window. A missing secret is not an error, but a server
+// without soft-delete support is: its 404 would otherwise look like success.
+func (c *APIClient) SoftDeleteSecret(ctx context.Context, ns, key string) error {
+	ns, err := c.resolveNamespace(ns)
+	if err != nil {
+		return err
+	}
+	if err := c.checkProtected(ctx, ns, key); err != nil {
+		return err
+	}
+	defer c.nsCache.invalidate(ns)
+	// POST /v2/configurations/:namespace/latest/:key/soft-delete
+	deleteURL := fmt.Sprintf("%s/%s/configurations/%s/latest/%s/soft-delete", c.baseURL, c.apiVersion, escapeNamespace(ns), url.PathEscape(c.fullKey(key)))
+	found, err := c.doJSON(ctx, "soft delete secret", "POST", deleteURL, nil, nil)
+	if err != nil || found {
+		return err
+	}
+	still, err := c.getSecret(ctx, ns, key, readOptions{primary: true})
+	if err != nil {
+		return err
+	}
+	if still != nil {
+		return fmt.Errorf("soft delete of %s/%s returned 404 although the secret exists; the server does not appear to support soft deletes", ns, key)
+	}
+	return nil
+}
+
+// GetDeletedSecret returns the soft-delete record of ns/key, or nil if the
+// secret is not soft-deleted: it exists, was purged, or never existed.
+func (c *APIClient) GetDeletedSecret(ctx context.Context, ns, key string) (*DeletedSecret, error) {
+	ns, err := c.resolveNamespace(ns)
+	if err != nil {
+		return nil, err
+	}
+	// GET /v2/configurations/:namespace/deleted/:key
+	deletedURL := fmt.Sprintf("%s/%s/configurations/%s/deleted/%s", c.baseURL, c.apiVersion, escapeNamespace(ns), url.PathEscape(c.fullKey(key)))
+	var out DeletedSecret
+	found, err := c.doJSON(ctx, "get deleted secret", "GET", deletedURL, nil, &out)
+	if err != nil || !found {
+		return nil, err
+	}
+	out.Namespace = ns
+	out.Key = key
+	return &out, nil
+}
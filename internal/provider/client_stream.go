@@ -0,0 +1,89 @@
+package provider
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"unicode/utf8"
+)
+
+const (
+	defaultStreamingThreshold = 1 << 20 // 1 MiB
+
+	// streamChunkSize is how much of a value is JSON-escaped at a time.
+	streamChunkSize = 64 << 10
+)
+
+// streamUpsertBody returns the PUT body for p, encoded on the fly so the JSON
+// copy of a large value is never held in memory at once. The bytes are
+// identical to json.Marshal of the equivalent upsertRequest.
+func streamUpsertBody(p SecretPayload) io.ReadCloser {
+	pr, pw := io.Pipe()
+	go func() {
+		w := bufio.NewWriterSize(pw, streamChunkSize)
+		err := writeUpsertJSON(w, p)
+		if err == nil {
+			err = w.Flush()
+		}
+		// The transport closes pr when it stops reading, which unblocks us.
+		pw.CloseWithError(err)
+	}()
+	return pr
+}
+
+func writeUpsertJSON(w io.Writer, p SecretPayload) error {
+	key, _ := json.Marshal(p.Key)
+	if _, err := fmt.Fprintf(w, `{"configs":{%s:"`, key); err != nil {
+		return err
+	}
+	for start := 0; start < len(p.Value); {
+		end := min(start+streamChunkSize, len(p.Value))
+		// Never split a UTF-8 sequence between chunks, or it would be
+		// escaped as invalid.
+		for i := 0; i < utf8.UTFMax-1 && end < len(p.Value) && end > start+1 && !utf8.RuneStart(p.Value[end]); i++ {
+			end--
+		}
+		chunk, _ := json.Marshal(p.Value[start:end])
+		if _, err := w.Write(chunk[1 : len(chunk)-1]); err != nil {
+			return err
+		}
+		start = end
+	}
+	if _, err := io.WriteString(w, `"}`); err != nil {
+		return err
+	}
+	for _, f := range []struct {
+		name string
+		m    map[string]string
+	}{{"labels", p.Labels}, {"tags", p.Tags}} {
+		if len(f.m) == 0 {
+			continue
+		}
+		b, err := json.Marshal(f.m)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, `,%q:%s`, f.name, b); err != nil {
+			return err
+		}
+	}
+	_, err := io.WriteString(w, "}")
+	return err
+}
+
+// decodeBody decodes a JSON response directly from the connection instead of
+// buffering it first, still enforcing max_response_bytes.
+func (c *APIClient) decodeBody(res *http.Response, out interface{}) error {
+	err := json.NewDecoder(http.MaxBytesReader(nil, res.Body, c.maxResponseBytes)).Decode(out)
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return fmt.Errorf("response body exceeds max_response_bytes (%d bytes)", c.maxResponseBytes)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
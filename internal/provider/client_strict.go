@@ -0,0 +1,92 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+)
+
+type unknownFieldsKey struct{}
+
+// unknownFields collects the unrecognized response fields seen during one
+// resource or data source operation.
+type unknownFields struct {
+	mu     sync.Mutex
+	fields []string
+}
+
+// collectUnknownFields returns ctx carrying a collector for the responses to
+// one operation's requests, and a function that reports what was collected as
+// a warning on that operation's diagnostics. Collecting per operation, rather
+// than per client, keeps the warning on the resource whose request saw the
+// field when operations run in parallel.
+func collectUnknownFields(ctx context.Context) (context.Context, func(*diag.Diagnostics)) {
+	col := &unknownFields{}
+	return context.WithValue(ctx, unknownFieldsKey{}, col), col.report
+}
+
+// checkUnknownFields decodes b once more into a fresh value of out's type with
+// unknown fields disallowed, when strict_response_parsing is set. An
+// unrecognized field is recorded on ctx's collector; the lenient decode the
+// caller already did stays authoritative.
+func (c *APIClient) checkUnknownFields(ctx context.Context, op string, b []byte, out interface{}) {
+	if !c.strictParsing || out == nil {
+		return
+	}
+	fresh := reflect.New(reflect.TypeOf(out).Elem()).Interface()
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	err := dec.Decode(fresh)
+	if err == nil {
+		return
+	}
+	// Decoding stops at the first unknown field, so one is reported per response.
+	field, ok := strings.CutPrefix(err.Error(), "json: unknown field ")
+	if !ok {
+		return
+	}
+	msg := fmt.Sprintf("%s: %s", op, field)
+	c.strictMu.Lock()
+	if c.unknownFieldsSeen == nil {
+		c.unknownFieldsSeen = map[string]bool{}
+	}
+	seen := c.unknownFieldsSeen[msg]
+	c.unknownFieldsSeen[msg] = true
+	c.strictMu.Unlock()
+	if seen {
+		return
+	}
+	col, ok := ctx.Value(unknownFieldsKey{}).(*unknownFields)
+	if !ok {
+		log.Printf("[WARN] Unrecognized response field (strict_response_parsing): %s", msg)
+		return
+	}
+	col.mu.Lock()
+	defer col.mu.Unlock()
+	col.fields = append(col.fields, msg)
+}
+
+// report adds a warning for the collected fields. Each field is reported once
+// per provider run, by the first operation that saw it.
+func (u *unknownFields) report(diags *diag.Diagnostics) {
+	u.mu.Lock()
+	pending := u.fields
+	u.fields = nil
+	u.mu.Unlock()
+	if len(pending) == 0 {
+		return
+	}
+	sort.Strings(pending)
+	diags.AddWarning("Unrecognized response fields",
+		"The server returned fields this provider version does not know (strict_response_parsing):\n  "+
+			strings.Join(pending, "\n  ")+
+			"\n\nThe Yggdrasil API may have changed. The fields were ignored.")
+}
@@ -0,0 +1,385 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+)
+
+func TestUpsertRequestEncodingIsCanonical(t *testing.T) {
+	keys := []string{"team", "env", "owner", "tier", "cost-center", "region"}
+	build := func(order []string) map[string]string {
+		m := map[string]string{}
+		for _, k := range order {
+			m[k] = "v-" + k
+		}
+		return m
+	}
+	reversed := make([]string, len(keys))
+	for i, k := range keys {
+		reversed[len(keys)-1-i] = k
+	}
+
+	srv := newFakeServer(t)
+	c := newTestClient(t, srv.URL, Config{})
+	for _, order := range [][]string{keys, reversed} {
+		_, err := c.UpsertSecret(context.Background(), SecretPayload{
+			Namespace: "team",
+			Key:       "db_password",
+			Value:     "s3cret",
+			Tags:      build(order),
+			Labels:    build(order),
+		})
+		if err != nil {
+			t.Fatalf("UpsertSecret: %v", err)
+		}
+	}
+
+	bodies := srv.bodies(http.MethodPut)
+	if len(bodies) != 2 {
+		t.Fatalf("got %d PUTs, want 2", len(bodies))
+	}
+	if !bytes.Equal(bodies[0], bodies[1]) {
+		t.Errorf("same payload encoded differently:\n%s\n%s", bodies[0], bodies[1])
+	}
+	want := `{"configs":{"db_password":"s3cret"},"labels":{"cost-center":"v-cost-center","env":"v-env","owner":"v-owner","region":"v-region","team":"v-team","tier":"v-tier"},"tags":{"cost-center":"v-cost-center","env":"v-env","owner":"v-owner","region":"v-region","team":"v-team","tier":"v-tier"}}`
+	if got := string(bytes.TrimSpace(bodies[0])); got != want {
+		t.Errorf("body = %s\nwant  %s", got, want)
+	}
+}
+
+func TestNormalizeAndEscapeNamespace(t *testing.T) {
+	tests := []struct {
+		in, normalized, escaped string
+	}{
+		{"team/prod", "team/prod", "team/prod"},
+		{"/team/prod/", "team/prod", "team/prod"},
+		{"team//prod", "team/prod", "team/prod"},
+		{"//team///prod//", "team/prod", "team/prod"},
+		{"/", "", ""},
+		{"", "", ""},
+		{"team a/prod", "team a/prod", "team%20a/prod"},
+		{"100%/prod", "100%/prod", "100%25/prod"},
+		{"team?/prod", "team?/prod", "team%3F/prod"},
+		{"/a b//c%d/e?f/", "a b/c%d/e?f", "a%20b/c%25d/e%3Ff"},
+	}
+	for _, tt := range tests {
+		if got := normalizeNamespace(tt.in); got != tt.normalized {
+			t.Errorf("normalizeNamespace(%q) = %q, want %q", tt.in, got, tt.normalized)
+		}
+		if got := escapeNamespace(tt.in); got != tt.escaped {
+			t.Errorf("escapeNamespace(%q) = %q, want %q", tt.in, got, tt.escaped)
+		}
+	}
+}
+
+func TestClientDeletesRefuseProtectedSecrets(t *testing.T) {
+	srv := newFakeServer(t)
+	srv.put("team", "locked", "x", map[string]string{"protected": "yes"})
+	srv.put("team", "free", "y", nil)
+	c := newTestClient(t, srv.URL, Config{ProtectedTag: "protected"})
+	ctx := context.Background()
+
+	var protErr *protectedError
+	if err := c.DeleteSecret(ctx, "team", "locked"); !errors.As(err, &protErr) {
+		t.Errorf("DeleteSecret: err = %v, want *protectedError", err)
+	}
+	if err := c.WriteSecrets(ctx, "team", nil, []string{"free", "locked"}); !errors.As(err, &protErr) {
+		t.Errorf("WriteSecrets: err = %v, want *protectedError", err)
+	}
+	ops := []TransactionOp{{Action: "delete", Namespace: "team", Key: "locked"}}
+	if _, err := c.CommitTransaction(ctx, ops); !errors.As(err, &protErr) {
+		t.Errorf("CommitTransaction: err = %v, want *protectedError", err)
+	}
+	if n := srv.count(http.MethodPut, "") + srv.count(http.MethodPost, ""); n != 0 {
+		t.Fatalf("%d writes reached the server, want none", n)
+	}
+	if got := srv.keys("team"); len(got) != 2 {
+		t.Errorf("namespace holds %v, want both keys", got)
+	}
+
+	if err := c.DeleteSecret(withForceDelete(ctx), "team", "locked"); err != nil {
+		t.Errorf("forced DeleteSecret: %v", err)
+	}
+	if err := c.DeleteSecret(ctx, "team", "free"); err != nil {
+		t.Errorf("DeleteSecret of an unprotected key: %v", err)
+	}
+	if got := srv.keys("team"); len(got) != 0 {
+		t.Errorf("namespace holds %v, want it empty", got)
+	}
+}
+
+func TestGraphQLClientRejectsRESTOnlyOperations(t *testing.T) {
+	srv := newFakeServer(t)
+	c := newTestClient(t, srv.URL, Config{Protocol: protocolGraphQL, GraphQLEndpoint: srv.URL + "/graphql"})
+	ctx := context.Background()
+
+	calls := map[string]func() error{
+		"ListSecrets":          func() error { _, err := c.ListSecrets(ctx, "team"); return err },
+		"GetNamespace":         func() error { _, err := c.GetNamespace(ctx, "team"); return err },
+		"WriteSecrets":         func() error { return c.WriteSecrets(ctx, "team", map[string]string{"a": "1"}, nil) },
+		"GetNamespaceMetadata": func() error { _, err := c.GetNamespaceMetadata(ctx, "team"); return err },
+		"GetNamespaceTags":     func() error { _, err := c.GetNamespaceTags(ctx, "team"); return err },
+		"GetAlias":             func() error { _, err := c.GetAlias(ctx, "team", "a"); return err },
+		"AcquireLock":          func() error { _, err := c.AcquireLock(ctx, "team", "a"); return err },
+		"SoftDeleteSecret":     func() error { return c.SoftDeleteSecret(ctx, "team", "a") },
+		"GetDeletedSecret":     func() error { _, err := c.GetDeletedSecret(ctx, "team", "a"); return err },
+		"SearchSecrets":        func() error { _, err := c.SearchSecrets(ctx, SearchQuery{KeyGlob: "*"}); return err },
+		"RawRequest": func() error {
+			_, _, err := c.RawRequest(ctx, "GET", "/v2/configurations/team/latest/all", nil)
+			return err
+		},
+	}
+	for name, call := range calls {
+		err := call()
+		if err == nil || !strings.Contains(err.Error(), `not supported with protocol = "graphql"`) {
+			t.Errorf("%s: err = %v, want a protocol error", name, err)
+		}
+	}
+	if n := len(srv.bodies(http.MethodGet)) + len(srv.bodies(http.MethodPut)) + len(srv.bodies(http.MethodPost)); n != 0 {
+		t.Errorf("%d requests reached the server, want none", n)
+	}
+	if supported, err := c.CommitTransaction(ctx, []TransactionOp{{Action: "upsert", Namespace: "team", Key: "a", Value: "1"}}); supported || err != nil {
+		t.Errorf("CommitTransaction = %t, %v; want unsupported so that operations are applied one by one", supported, err)
+	}
+}
+
+func TestCommitTransactionIsNotRetriedAndReportsUnknownOutcome(t *testing.T) {
+	var posts atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		posts.Add(1)
+		http.Error(w, "upstream timed out", http.StatusBadGateway)
+	}))
+	defer srv.Close()
+	c := newTestClient(t, srv.URL, Config{})
+
+	_, err := c.CommitTransaction(context.Background(), []TransactionOp{{Action: "upsert", Namespace: "team", Key: "a", Value: "1"}})
+	if !errors.Is(err, errCommitUnknown) {
+		t.Errorf("err = %v, want errCommitUnknown", err)
+	}
+	if n := posts.Load(); n != 1 {
+		t.Errorf("commit was sent %d times, want once", n)
+	}
+}
+
+func TestHeadersFlightKeyDoesNotLeakHeaders(t *testing.T) {
+	ctx := withHeaders(context.Background(), map[string]string{"X-Vault-Token": "hunter2"})
+	key := headersFlightKey(ctx)
+	if strings.Contains(key, "hunter2") || strings.Contains(key, "X-Vault-Token") {
+		t.Errorf("flight key %q contains the headers", key)
+	}
+	other := headersFlightKey(withHeaders(context.Background(), map[string]string{"X-Vault-Token": "other"}))
+	if key == other || key == "" {
+		t.Errorf("flight keys %q and %q should differ and be non-empty", key, other)
+	}
+	if got := headersFlightKey(context.Background()); got != "" {
+		t.Errorf("flight key without headers = %q, want empty", got)
+	}
+}
+
+func TestRawRequestEnforcesNamespacePolicy(t *testing.T) {
+	srv := newFakeServer(t)
+	srv.put("team-a", "k", "v", nil)
+	srv.put("team-b", "k", "v", nil)
+	c := newTestClient(t, srv.URL, Config{AllowedNamespaces: []string{"team-a", "team-a/*"}})
+	ctx := context.Background()
+
+	tests := []struct {
+		path string
+		ok   bool
+	}{
+		{"/v2/configurations/team-a/latest/all", true},
+		{"/v2/configurations/team-a/sub/latest/all", true},
+		{"/v2/configurations/team-a/3/all", true},
+		{"/v2/configurations/team-a/sub/deleted/k", true},
+		{"/v2/configurations/team-a", true},
+		{"/v2/namespaces/team-a", true},
+		{"/v2/configurations/team-b/latest/all", false},
+		{"/v2/configurations/%74eam-b/latest/all", false},
+		{"/v2/configurations/team-a/../team-b/latest/all", false},
+		{"/v2/namespaces/team-b", false},
+		{"/v2/configurations", false},
+		{"/v2/search?key=*", false},
+		{"/v2/transactions", false},
+		{"/v1/configurations/team-a/latest/all", false},
+	}
+	for _, tt := range tests {
+		_, _, err := c.RawRequest(ctx, "GET", tt.path, nil)
+		// The fake server 404s some allowed paths; only policy errors matter here.
+		if blocked := err != nil && errorStatus(err) == 0; blocked == tt.ok {
+			t.Errorf("RawRequest(%q): err = %v, want allowed = %t", tt.path, err, tt.ok)
+		}
+	}
+	if n := srv.count(http.MethodGet, "/team-b/latest/all"); n != 0 {
+		t.Errorf("%d requests for team-b reached the server", n)
+	}
+
+	// Without a policy, any path is passed through.
+	open := newTestClient(t, srv.URL, Config{})
+	if _, _, err := open.RawRequest(ctx, "GET", "/v2/configurations/team-b/latest/all", nil); err != nil {
+		t.Errorf("RawRequest without a policy: %v", err)
+	}
+}
+
+func TestUnknownFieldsAreReportedOnTheOperationThatSawThem(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if strings.Contains(string(body), `"new_field_key"`) {
+			_, _ = w.Write([]byte(`{"version":2,"shiny_new_field":true}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"version":2}`))
+	}))
+	defer srv.Close()
+	c := newTestClient(t, srv.URL, Config{StrictParsing: true})
+
+	var wg sync.WaitGroup
+	diags := map[string]*diag.Diagnostics{"new_field_key": {}, "plain_key": {}}
+	for key, d := range diags {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, report := collectUnknownFields(context.Background())
+			defer report(d)
+			if _, err := c.UpsertSecret(ctx, SecretPayload{Namespace: "team", Key: key, Value: "v"}); err != nil {
+				t.Errorf("UpsertSecret(%s): %v", key, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if d := diags["new_field_key"]; d.WarningsCount() != 1 || !strings.Contains(d.Warnings()[0].Detail(), "shiny_new_field") {
+		t.Errorf("operation that saw the field got %v, want one warning naming it", *d)
+	}
+	if d := diags["plain_key"]; d.WarningsCount() != 0 {
+		t.Errorf("other operation got %v, want no warnings", *d)
+	}
+}
+
+func TestGetSecretAsOfReportsMissingServerSupport(t *testing.T) {
+	// The fake server has no at/ route, so every as-of read 404s.
+	srv := newFakeServer(t)
+	srv.put("team", "db_password", "s3cret", nil)
+	c := newTestClient(t, srv.URL, Config{})
+	ctx := context.Background()
+	at := time.Date(2024, 5, 1, 14, 5, 0, 0, time.UTC)
+
+	_, err := c.GetSecretAsOf(ctx, "team", "db_password", at)
+	if err == nil || !strings.Contains(err.Error(), "does not support reads as of a timestamp") {
+		t.Errorf("err = %v, want missing support reported", err)
+	}
+	if n := srv.count(http.MethodGet, "/at/2024-05-01T14:05:00Z/all"); n != 1 {
+		t.Errorf("%d as-of reads reached the server, want 1", n)
+	}
+
+	// A namespace that does not exist at all is simply not found.
+	out, err := c.GetSecretAsOf(ctx, "other", "db_password", at)
+	if out != nil || err != nil {
+		t.Errorf("GetSecretAsOf of a missing namespace = %+v, %v; want nil, nil", out, err)
+	}
+}
+
+func TestUnwrapNamespaceReadsLabelsAndTagsOnlyBesideConfigs(t *testing.T) {
+	var wrapped, flat map[string]interface{}
+	if err := json.Unmarshal([]byte(`{"data":{"configs":{"a":"1","b":"2"},"labels":{"a":{"tier":"gold"}},"tags":{"b":{"owner":"platform","n":3}}}}`), &wrapped); err != nil {
+		t.Fatal(err)
+	}
+	read := unwrapNamespace(wrapped)
+	if got, want := read.configs, map[string]interface{}{"a": "1", "b": "2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("configs = %v, want %v", got, want)
+	}
+	if got, want := read.labels, map[string]map[string]string{"a": {"tier": "gold"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("labels = %v, want %v", got, want)
+	}
+	if got, want := read.tags, map[string]map[string]string{"b": {"owner": "platform"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("tags = %v, want %v", got, want)
+	}
+
+	if err := json.Unmarshal([]byte(`{"labels":"a secret named labels","a":"1"}`), &flat); err != nil {
+		t.Fatal(err)
+	}
+	read = unwrapNamespace(flat)
+	if len(read.configs) != 2 || read.labels != nil || read.tags != nil {
+		t.Errorf("flat map read as %+v, want two keys and no labels or tags", read)
+	}
+}
+
+func TestRawRequestRetriesOnlyIdempotentMethods(t *testing.T) {
+	// Every method's first request gets a 502, later ones succeed.
+	var mu sync.Mutex
+	sent := map[string]int{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		sent[r.Method]++
+		first := sent[r.Method] == 1
+		mu.Unlock()
+		if first {
+			http.Error(w, "upstream timed out", http.StatusBadGateway)
+			return
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+	c := newTestClient(t, srv.URL, Config{})
+	ctx := context.Background()
+
+	for _, method := range []string{"POST", "PATCH"} {
+		if _, _, err := c.RawRequest(ctx, method, "/v2/jobs", []byte(`{}`)); errorStatus(err) != http.StatusBadGateway {
+			t.Errorf("%s: err = %v, want the 502", method, err)
+		}
+	}
+	if _, _, err := c.RawRequest(ctx, "PUT", "/v2/jobs/1", []byte(`{}`)); err != nil {
+		t.Errorf("PUT was not retried: %v", err)
+	}
+	want := map[string]int{"POST": 1, "PATCH": 1, "PUT": 2}
+	if !reflect.DeepEqual(sent, want) {
+		t.Errorf("requests sent per method = %v, want %v", sent, want)
+	}
+}
+
+func TestGetSecretTakesVersionFromNamespaceHeaders(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("X-Config-Version", "42")
+		w.Header().Set("Last-Modified", "Wed, 01 May 2024 14:05:00 GMT")
+		_, _ = w.Write([]byte(`{"db_password":"s3cret"}`))
+	}))
+	defer srv.Close()
+	c := newTestClient(t, srv.URL, Config{})
+
+	out, err := c.GetSecret(context.Background(), "team", "db_password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.Version != 42 || out.UpdatedAt != "2024-05-01T14:05:00Z" {
+		t.Errorf("version, updated_at = %d, %q; want 42, %q", out.Version, out.UpdatedAt, "2024-05-01T14:05:00Z")
+	}
+}
+
+func TestGetSecretTagsEscapesTheKey(t *testing.T) {
+	var path, query string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, query = r.URL.Path, r.URL.RawQuery
+		_, _ = w.Write([]byte(`{"tags":{"owner":"platform"}}`))
+	}))
+	defer srv.Close()
+	c := newTestClient(t, srv.URL, Config{})
+
+	if _, err := c.GetSecretTags(context.Background(), "team", "a b?c"); err != nil {
+		t.Fatal(err)
+	}
+	if want := "/v2/configurations/team/latest/a b?c/tags"; path != want || query != "" {
+		t.Errorf("request path = %q, query = %q; want %q and no query", path, query, want)
+	}
+}
@@ -0,0 +1,136 @@
+package provider
+
+import (
+	"context"
+	"log"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultAdaptiveTimeoutMin = 5 * time.Second
+
+	// adaptiveWindow is how many recent successful operations per type feed the
+	// median; adaptiveMinSamples are needed before it replaces request_timeout.
+	adaptiveWindow     = 20
+	adaptiveMinSamples = 3
+	// adaptiveMultiplier leaves room for the slow tail above the median.
+	adaptiveMultiplier = 4
+)
+
+// latencyTracker keeps a rolling window of operation durations per operation
+// type and derives a deadline from their median.
+type latencyTracker struct {
+	min, max time.Duration
+	initial  time.Duration // used until an operation type has enough samples
+
+	mu      sync.Mutex
+	samples map[string][]time.Duration
+}
+
+// newLatencyTracker returns a tracker whose deadlines lie in [min, max]. max
+// may exceed initial (request_timeout); zero means initial.
+func newLatencyTracker(min, initial, max time.Duration) *latencyTracker {
+	if min <= 0 {
+		min = defaultAdaptiveTimeoutMin
+	}
+	if max <= 0 {
+		max = initial
+	}
+	if min > max {
+		min = max
+	}
+	return &latencyTracker{min: min, max: max, initial: initial, samples: map[string][]time.Duration{}}
+}
+
+func (t *latencyTracker) observe(op string, d time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	s := append(t.samples[op], d)
+	if len(s) > adaptiveWindow {
+		s = s[len(s)-adaptiveWindow:]
+	}
+	t.samples[op] = s
+}
+
+// timeout returns adaptiveMultiplier times the median duration of op, clamped
+// to [min, max]. Until enough samples exist it returns initial.
+func (t *latencyTracker) timeout(op string) time.Duration {
+	t.mu.Lock()
+	s := append([]time.Duration(nil), t.samples[op]...)
+	t.mu.Unlock()
+	if len(s) < adaptiveMinSamples {
+		return t.initial
+	}
+	sort.Slice(s, func(i, j int) bool { return s[i] < s[j] })
+	d := s[len(s)/2] * adaptiveMultiplier
+	if d < t.min {
+		return t.min
+	}
+	if d > t.max {
+		return t.max
+	}
+	return d
+}
+
+type opKey struct{}
+
+type opOutcomeKey struct{}
+
+// opOutcome records whether every request of an operation ended in a 2xx
+// response. Only such operations feed the latency tracker: errors, 4xx and 5xx
+// responses often return much faster (or slower) than real work does.
+type opOutcome struct {
+	responded, failed atomic.Bool
+}
+
+// recordOutcome notes the final status of one request made under ctx; zero
+// means the request got no response.
+func recordOutcome(ctx context.Context, status int) {
+	o, ok := ctx.Value(opOutcomeKey{}).(*opOutcome)
+	if !ok {
+		return
+	}
+	if status < 200 || status > 299 {
+		o.failed.Store(true)
+		return
+	}
+	o.responded.Store(true)
+}
+
+// opFromContext returns the operation name withTimeout attached to ctx.
+func opFromContext(ctx context.Context) string {
+	if op, ok := ctx.Value(opKey{}).(string); ok {
+		return op
+	}
+	return "other"
+}
+
+// withTimeout bounds a whole API operation, retries included. With
+// adaptive_timeout the deadline follows the observed latency of op, otherwise
+// it is request_timeout. A shorter deadline or cancellation already on ctx
+// (from Terraform) still wins, and a resource's timeouts block replaces both.
+func (c *APIClient) withTimeout(ctx context.Context, op string) (context.Context, context.CancelFunc) {
+	ctx = context.WithValue(ctx, opKey{}, op)
+	if deadline, ok := ctx.Value(operationTimeoutKey{}).(time.Time); ok {
+		return context.WithDeadline(ctx, deadline)
+	}
+	if c.latency == nil {
+		return context.WithTimeout(ctx, c.requestTimeout)
+	}
+	timeout := c.latency.timeout(op)
+	log.Printf("[DEBUG] Adaptive timeout for %s: %s", op, timeout)
+	outcome := &opOutcome{}
+	ctx, cancel := context.WithTimeout(context.WithValue(ctx, opOutcomeKey{}, outcome), timeout)
+	start := time.Now()
+	return ctx, func() {
+		// Operations cut short by a deadline or cancellation, or that failed,
+		// say nothing about how long the server takes to do the work.
+		if ctx.Err() == nil && outcome.responded.Load() && !outcome.failed.Load() {
+			c.latency.observe(op, time.Since(start))
+		}
+		cancel()
+	}
+}
@@ -0,0 +1,68 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAdaptiveTimeoutObservesOnlySuccessfulOperations(t *testing.T) {
+	var fail atomic.Bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if fail.Load() {
+			http.Error(w, "denied", http.StatusForbidden)
+			return
+		}
+		_, _ = w.Write([]byte(`{"db_password":"s3cret"}`))
+	}))
+	defer srv.Close()
+	c := newTestClient(t, srv.URL, Config{AdaptiveTimeout: true, RequestTimeout: time.Minute, NamespaceCacheTTL: new(time.Duration)})
+	ctx := context.Background()
+
+	fail.Store(true)
+	for i := 0; i < adaptiveMinSamples; i++ {
+		if _, err := c.GetSecret(ctx, "team", "db_password"); err == nil {
+			t.Fatal("GetSecret succeeded against a failing server")
+		}
+	}
+	if got := c.latency.timeout("read"); got != time.Minute {
+		t.Fatalf("timeout after failed reads = %s, want request_timeout", got)
+	}
+
+	fail.Store(false)
+	for i := 0; i < adaptiveMinSamples; i++ {
+		if _, err := c.GetSecret(ctx, "team", "db_password"); err != nil {
+			t.Fatalf("GetSecret: %v", err)
+		}
+	}
+	if got := c.latency.timeout("read"); got != defaultAdaptiveTimeoutMin {
+		t.Errorf("timeout after fast successful reads = %s, want %s", got, defaultAdaptiveTimeoutMin)
+	}
+}
+
+func TestAdaptiveTimeoutMaxMayExceedRequestTimeout(t *testing.T) {
+	tr := newLatencyTracker(time.Second, 10*time.Second, time.Minute)
+	if got := tr.timeout("write"); got != 10*time.Second {
+		t.Errorf("timeout without samples = %s, want request_timeout", got)
+	}
+	for i := 0; i < adaptiveMinSamples; i++ {
+		tr.observe("write", 5*time.Second)
+	}
+	if got, want := tr.timeout("write"), 5*time.Second*adaptiveMultiplier; got != want {
+		t.Errorf("timeout = %s, want %s", got, want)
+	}
+	for i := 0; i < adaptiveWindow; i++ {
+		tr.observe("write", time.Hour)
+	}
+	if got := tr.timeout("write"); got != time.Minute {
+		t.Errorf("timeout = %s, want it capped at adaptive_timeout_max", got)
+	}
+
+	// Without adaptive_timeout_max, request_timeout is the cap.
+	if got := newLatencyTracker(0, 10*time.Second, 0).max; got != 10*time.Second {
+		t.Errorf("default max = %s, want request_timeout", got)
+	}
+}
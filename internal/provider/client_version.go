@@ -0,0 +1,54 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"slices"
+)
+
+// serverInfo is the body of GET /info, e.g. {"api_versions": ["v1", "v2"]}.
+// Servers that speak a single version may advertise only "api_version".
+type serverInfo struct {
+	APIVersion  string   `json:"api_version"`
+	APIVersions []string `json:"api_versions"`
+}
+
+// ServerAPIVersions returns the API versions the server advertises on its
+// unversioned info endpoint, or nil if it has none or advertises nothing.
+func (c *APIClient) ServerAPIVersions(ctx context.Context) ([]string, error) {
+	var info serverInfo
+	found, err := c.doJSON(ctx, "server info", "GET", c.baseURL+"/info", nil, &info)
+	if err != nil || !found {
+		return nil, err
+	}
+	versions := info.APIVersions
+	if info.APIVersion != "" && !slices.Contains(versions, info.APIVersion) {
+		versions = append(versions, info.APIVersion)
+	}
+	return versions, nil
+}
+
+// apiVersionMismatchError reports that the server does not serve the
+// configured api_version.
+type apiVersionMismatchError struct {
+	Configured string
+	Supported  []string
+}
+
+func (e *apiVersionMismatchError) Error() string {
+	return fmt.Sprintf("api_version is %q, but the server supports %q", e.Configured, e.Supported)
+}
+
+// CheckAPIVersion verifies that the server serves the configured api_version.
+// A server without an info endpoint is not an error, as there is nothing to
+// compare against; a mismatch is an *apiVersionMismatchError.
+func (c *APIClient) CheckAPIVersion(ctx context.Context) error {
+	versions, err := c.ServerAPIVersions(ctx)
+	if err != nil {
+		return err
+	}
+	if len(versions) > 0 && !slices.Contains(versions, c.apiVersion) {
+		return &apiVersionMismatchError{Configured: c.apiVersion, Supported: versions}
+	}
+	return nil
+}
@@ -1,12 +1,41 @@
 package provider
 
+import (
+	"regexp"
+	"time"
+)
+
 type Config struct {
-	Endpoint           string
-	Token              string
-	NamespaceDefault   string
-	InsecureSkipVerify bool
-	CACertPath         string
-	ClientCertPath     string
-	ClientKeyPath      string
-	APIVersion         string // e.g. "v2"
+	Endpoint            string
+	ReadEndpoint        string // empty means Endpoint
+	Token               string
+	NamespaceDefault    string
+	InsecureSkipVerify  bool
+	CACertPath          string
+	ClientCertPath      string
+	ClientKeyPath       string
+	TLSPinSHA256        []string // normalized base64 SPKI fingerprints; empty disables pinning
+	APIVersion          string   // e.g. "v2"
+	RetryStatusCodes    []int    // nil means defaultRetryStatusCodes
+	AuditLogPath        string
+	RedactPathPatterns  []*regexp.Regexp
+	RequestTimeout      time.Duration // zero means defaultRequestTimeout
+	AdaptiveTimeout     bool
+	AdaptiveTimeoutMin  time.Duration // zero means defaultAdaptiveTimeoutMin
+	AdaptiveTimeoutMax  time.Duration // zero means RequestTimeout
+	MetricsListenAddr   string
+	NamespaceCacheTTL   *time.Duration // nil means defaultNamespaceCacheTTL; zero disables the cache
+	StrictParsing       bool
+	AssumeReadFromState bool
+	ContentType         string // empty means defaultContentType, and no Accept header
+	DefaultChangeReason string
+	KeyPrefix           string
+	MaxResponseBytes    int64  // zero means defaultMaxResponseBytes
+	StreamingThreshold  int64  // zero means defaultStreamingThreshold
+	ProtectedTag        string // "name" or "name=value"
+	AutoCreateNamespace bool
+	AllowedNamespaces   []string // glob patterns; empty allows all
+	DeniedNamespaces    []string // glob patterns; take precedence over AllowedNamespaces
+	Protocol            string   // protocolREST (or empty) or protocolGraphQL
+	GraphQLEndpoint     string
 }
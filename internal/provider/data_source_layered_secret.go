@@ -0,0 +1,128 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	dsSchema "github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	tfTypes "github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var _ datasource.DataSource = &LayeredSecretDataSource{}
+
+func NewLayeredSecretDataSource() datasource.DataSource {
+	return &LayeredSecretDataSource{}
+}
+
+// LayeredSecretDataSource resolves a key across an ordered list of namespaces,
+// where later namespaces override earlier ones.
+type LayeredSecretDataSource struct {
+	client *APIClient
+}
+
+type LayeredSecretDataModel struct {
+	ID                tfTypes.String `tfsdk:"id"`
+	Namespaces        tfTypes.List   `tfsdk:"namespaces"`
+	Key               tfTypes.String `tfsdk:"key"`
+	Required          tfTypes.Bool   `tfsdk:"required"`
+	Value             tfTypes.String `tfsdk:"value"`
+	ResolvedNamespace tfTypes.String `tfsdk:"resolved_namespace"`
+	Version           tfTypes.Int64  `tfsdk:"version"`
+	UpdatedAt         tfTypes.String `tfsdk:"updated_at"`
+}
+
+func (d *LayeredSecretDataSource) Metadata(_ context.Context, _ datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = "yggdrasil_layered_secret"
+}
+
+func (d *LayeredSecretDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = dsSchema.Schema{
+		Attributes: map[string]dsSchema.Attribute{
+			"namespaces": dsSchema.ListAttribute{
+				ElementType: tfTypes.StringType,
+				Required:    true,
+				Description: "Namespaces to search, lowest priority first. Later namespaces override earlier ones.",
+			},
+			"key": dsSchema.StringAttribute{
+				Required: true,
+			},
+			"required": dsSchema.BoolAttribute{
+				Optional:    true,
+				Description: "Fail when no namespace contains the key. Defaults to true; when false, `value` is null instead.",
+			},
+			"value": dsSchema.StringAttribute{
+				Computed:  true,
+				Sensitive: true,
+			},
+			"resolved_namespace": dsSchema.StringAttribute{
+				Computed:    true,
+				Description: "Namespace the value was taken from, normalized like `yggdrasil_secret` namespaces, or null when not found.",
+			},
+			"version": dsSchema.Int64Attribute{
+				Computed:    true,
+				Description: "Namespace version the secret was read at, or null when the server does not report it.",
+			},
+			"updated_at": dsSchema.StringAttribute{
+				Computed:    true,
+				Description: "Last modification time of the namespace (RFC3339), or null when the server does not report it.",
+			},
+			"id": dsSchema.StringAttribute{
+				Computed: true,
+			},
+		},
+	}
+}
+
+func (d *LayeredSecretDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, _ *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	d.client = req.ProviderData.(*APIClient)
+}
+
+func (d *LayeredSecretDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data LayeredSecretDataModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var namespaces []string
+	resp.Diagnostics.Append(data.Namespaces.ElementsAs(ctx, &namespaces, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	key := data.Key.ValueString()
+
+	data.ID = tfTypes.StringValue(fmt.Sprintf("%s/%s", strings.Join(namespaces, ","), key))
+	data.Value = tfTypes.StringNull()
+	data.ResolvedNamespace = tfTypes.StringNull()
+	data.Version = tfTypes.Int64Null()
+	data.UpdatedAt = tfTypes.StringNull()
+
+	// Highest priority is last, so walk the list backwards and stop at the first hit.
+	for i := len(namespaces) - 1; i >= 0; i-- {
+		out, err := d.client.GetSecret(ctx, namespaces[i], key)
+		if err != nil {
+			addAPIError(&resp.Diagnostics, "Read failed", fmt.Errorf("namespace %q: %w", namespaces[i], err))
+			return
+		}
+		if out == nil {
+			continue
+		}
+		data.Value = tfTypes.StringValue(out.Value)
+		data.ResolvedNamespace = tfTypes.StringValue(normalizeNamespace(namespaces[i]))
+		data.Version, data.UpdatedAt = versionValues(out)
+		resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+		return
+	}
+
+	if data.Required.IsNull() || data.Required.ValueBool() {
+		resp.Diagnostics.AddError("Not found",
+			fmt.Sprintf("Key %q does not exist in any of the namespaces %v", key, namespaces))
+		return
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
@@ -0,0 +1,64 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+// readLayeredSecret reads the yggdrasil_layered_secret data source for key
+// in namespaces.
+func readLayeredSecret(t *testing.T, c *APIClient, namespaces []string, key string) (tftypes.Value, diag.Diagnostics) {
+	t.Helper()
+	d := &LayeredSecretDataSource{client: c}
+	var sresp datasource.SchemaResponse
+	d.Schema(context.Background(), datasource.SchemaRequest{}, &sresp)
+	s := sresp.Schema
+	typ := s.Type().TerraformType(context.Background()).(tftypes.Object)
+
+	vals := map[string]tftypes.Value{}
+	for name, at := range typ.AttributeTypes {
+		vals[name] = tftypes.NewValue(at, nil)
+	}
+	nsVals := make([]tftypes.Value, len(namespaces))
+	for i, ns := range namespaces {
+		nsVals[i] = tfString(ns)
+	}
+	vals["namespaces"] = tftypes.NewValue(tftypes.List{ElementType: tftypes.String}, nsVals)
+	vals["key"] = tfString(key)
+	config := tftypes.NewValue(typ, vals)
+
+	resp := &datasource.ReadResponse{State: tfsdk.State{Schema: s, Raw: tftypes.NewValue(typ, nil)}}
+	d.Read(context.Background(), datasource.ReadRequest{Config: tfsdk.Config{Schema: s, Raw: config}}, resp)
+	return resp.State.Raw, resp.Diagnostics
+}
+
+func TestLayeredSecretResolvedNamespaceIsNormalized(t *testing.T) {
+	srv := newFakeServer(t)
+	srv.put("base", "db_password", "default", nil)
+	srv.put("team/prod", "db_password", "s3cret", nil)
+
+	state, diags := readLayeredSecret(t, newTestClient(t, srv.URL, Config{}), []string{"/base/", "/team//prod/"}, "db_password")
+	failOnError(t, "read", diags)
+	if got := attrString(t, state, "resolved_namespace"); got != "team/prod" {
+		t.Errorf("resolved_namespace = %q, want %q", got, "team/prod")
+	}
+}
+
+func TestLayeredSecretReportsAPIErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, "denied", http.StatusForbidden)
+	}))
+	defer srv.Close()
+
+	_, diags := readLayeredSecret(t, newTestClient(t, srv.URL, Config{}), []string{"base"}, "db_password")
+	if !diags.HasError() || diags[0].Summary() != "Read failed: permission denied" {
+		t.Errorf("diagnostics = %v, want a permission denied API error", diags)
+	}
+}
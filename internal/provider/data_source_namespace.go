@@ -0,0 +1,89 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	dsSchema "github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	tfTypes "github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var _ datasource.DataSource = &NamespaceDataSource{}
+
+func NewNamespaceDataSource() datasource.DataSource {
+	return &NamespaceDataSource{}
+}
+
+// NamespaceDataSource exposes a whole namespace: its key names and the raw
+// response body for tools that need the server's JSON exactly.
+type NamespaceDataSource struct {
+	client *APIClient
+}
+
+type NamespaceDataModel struct {
+	ID        tfTypes.String `tfsdk:"id"`
+	Namespace tfTypes.String `tfsdk:"namespace"`
+	Keys      tfTypes.List   `tfsdk:"keys"`
+	RawJSON   tfTypes.String `tfsdk:"raw_json"`
+}
+
+func (d *NamespaceDataSource) Metadata(_ context.Context, _ datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = "yggdrasil_namespace"
+}
+
+func (d *NamespaceDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = dsSchema.Schema{
+		Attributes: map[string]dsSchema.Attribute{
+			"namespace": dsSchema.StringAttribute{
+				Required: true,
+			},
+			"keys": dsSchema.ListAttribute{
+				ElementType: tfTypes.StringType,
+				Computed:    true,
+				Description: "Sorted key names in the namespace, with `key_prefix` stripped. Keys outside the prefix are omitted.",
+			},
+			"raw_json": dsSchema.StringAttribute{
+				Computed:    true,
+				Sensitive:   true,
+				Description: "Response body of the namespace read exactly as the server returned it, including all values and field order. Masked in logs, but stored unredacted in state.",
+			},
+			"id": dsSchema.StringAttribute{
+				Computed: true,
+			},
+		},
+	}
+}
+
+func (d *NamespaceDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, _ *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	d.client = req.ProviderData.(*APIClient)
+}
+
+func (d *NamespaceDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data NamespaceDataModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ns := data.Namespace.ValueString()
+	out, err := d.client.GetNamespace(ctx, ns)
+	if err != nil {
+		addAPIError(&resp.Diagnostics, "Read failed", err)
+		return
+	}
+	if out == nil {
+		resp.Diagnostics.AddError("Not found", fmt.Sprintf("Namespace %q does not exist", ns))
+		return
+	}
+	keys, diags := tfTypes.ListValueFrom(ctx, tfTypes.StringType, out.Keys)
+	resp.Diagnostics.Append(diags...)
+
+	data.ID = tfTypes.StringValue(out.Name)
+	data.Keys = keys
+	data.RawJSON = tfTypes.StringValue(string(out.RawJSON))
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
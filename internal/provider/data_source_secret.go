@@ -1,12 +1,20 @@
 package provider
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	dsSchema "github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	tfTypes "github.com/hashicorp/terraform-plugin-framework/types"
 )
 
@@ -28,6 +36,36 @@ type SecretDataModel struct {
 	Tags      tfTypes.Map    `tfsdk:"tags"`
 	Version   tfTypes.Int64  `tfsdk:"version"`
 	UpdatedAt tfTypes.String `tfsdk:"updated_at"`
+
+	ValueSHA256 tfTypes.String `tfsdk:"value_sha256"`
+	ResolveRefs tfTypes.Bool   `tfsdk:"resolve_refs"`
+	AsOf        tfTypes.String `tfsdk:"as_of"`
+
+	MinVersion        tfTypes.Int64  `tfsdk:"min_version"`
+	MinVersionTimeout tfTypes.String `tfsdk:"min_version_timeout"`
+
+	EncryptionContext tfTypes.Map `tfsdk:"encryption_context"`
+
+	ResponseHeaderNames tfTypes.List `tfsdk:"response_header_names"`
+	ResponseHeaders     tfTypes.Map  `tfsdk:"response_headers"`
+
+	Project   tfTypes.List `tfsdk:"project"`
+	Projected tfTypes.Map  `tfsdk:"projected"`
+
+	RequestHeaders tfTypes.Map `tfsdk:"request_headers"`
+}
+
+// versionValues returns out's version and updated_at, each null when the
+// server did not report it.
+func versionValues(out *SecretResponse) (tfTypes.Int64, tfTypes.String) {
+	version, updatedAt := tfTypes.Int64Null(), tfTypes.StringNull()
+	if out.Version != 0 {
+		version = tfTypes.Int64Value(int64(out.Version))
+	}
+	if out.UpdatedAt != "" {
+		updatedAt = tfTypes.StringValue(out.UpdatedAt)
+	}
+	return version, updatedAt
 }
 
 func (d *SecretDataSource) Metadata(_ context.Context, _ datasource.MetadataRequest, resp *datasource.MetadataResponse) {
@@ -38,24 +76,78 @@ func (d *SecretDataSource) Schema(_ context.Context, _ datasource.SchemaRequest,
 	resp.Schema = dsSchema.Schema{
 		Attributes: map[string]dsSchema.Attribute{
 			"namespace": dsSchema.StringAttribute{
-				Required: true,
+				Required:    true,
+				Description: "Namespace of the secret. Surrounding and repeated slashes are ignored, so `/team//prod/` addresses `team/prod`; IDs always use the normalized form.",
 			},
 			"key": dsSchema.StringAttribute{
 				Required: true,
 			},
+			"as_of": dsSchema.StringAttribute{
+				Optional:    true,
+				Description: "RFC3339 timestamp, e.g. `2024-05-01T14:05:00Z`. Returns the value and version that were current at that time. Fails if the server does not support time-travel reads. Cannot be combined with `resolve_refs`.",
+			},
+			"encryption_context": dsSchema.MapAttribute{
+				ElementType: tfTypes.StringType,
+				Optional:    true,
+				Description: "Envelope-encryption context (additional authenticated data) the secret was written with. Must match exactly.",
+			},
+			"min_version": dsSchema.Int64Attribute{
+				Optional:    true,
+				Description: "Wait until the namespace has reached at least this version before reading, e.g. to check that a published value has propagated to a replica. Fails if it is not reached within `min_version_timeout`, or if the server does not report namespace versions. Cannot be combined with `as_of` or `resolve_refs`.",
+			},
+			"min_version_timeout": dsSchema.StringAttribute{
+				Optional:    true,
+				Description: "How long `min_version` waits, as a Go duration. Defaults to `1m`.",
+			},
+			"project": dsSchema.ListAttribute{
+				ElementType: tfTypes.StringType,
+				Optional:    true,
+				Description: "Paths of fields to extract from a JSON value into `projected`, dot-separated with numeric array indexes, e.g. `db.password` or `replicas.0.host`. When set, `value` is left null so that only the projected fields are stored in state. Extraction happens in the provider after the whole value has been fetched.",
+			},
+			"projected": dsSchema.MapAttribute{
+				ElementType: tfTypes.StringType,
+				Computed:    true,
+				Sensitive:   true,
+				Description: "The fields selected by `project`, keyed by path. Strings are returned as is; other JSON values as compact JSON.",
+			},
+			"request_headers": dsSchema.MapAttribute{
+				ElementType: tfTypes.StringType,
+				Optional:    true,
+				Description: "Extra HTTP headers sent with the API requests of this data source, e.g. `{ \"X-Intent\" = \"read\" }` for a gateway policy engine. They override the provider's default headers such as `Accept`; authentication headers cannot be set.",
+			},
+			"resolve_refs": dsSchema.BoolAttribute{
+				Optional:    true,
+				Description: "Return the fully dereferenced value when the secret is stored as a `$ref: namespace/key` reference. Defaults to false, which returns the raw stored value.",
+			},
+			"response_header_names": dsSchema.ListAttribute{
+				ElementType: tfTypes.StringType,
+				Optional:    true,
+				Description: "Response headers to expose in `response_headers`, e.g. `X-Encrypted-With`. Authentication headers are never exposed.",
+			},
+			"response_headers": dsSchema.MapAttribute{
+				ElementType: tfTypes.StringType,
+				Computed:    true,
+				Description: "Values of the allowlisted response headers that were present, keyed as listed in `response_header_names`.",
+			},
 			"value": dsSchema.StringAttribute{
 				Computed:  true,
 				Sensitive: true,
 			},
+			"value_sha256": dsSchema.StringAttribute{
+				Computed:    true,
+				Description: "Hex SHA-256 of the stored value. Not sensitive.",
+			},
 			"tags": dsSchema.MapAttribute{
 				ElementType: tfTypes.StringType,
 				Computed:    true,
 			},
 			"version": dsSchema.Int64Attribute{
-				Computed: true,
+				Computed:    true,
+				Description: "Namespace version the secret was read at, or null when the server does not report it.",
 			},
 			"updated_at": dsSchema.StringAttribute{
-				Computed: true,
+				Computed:    true,
+				Description: "Last modification time of the namespace (RFC3339), or null when the server does not report it.",
 			},
 			"id": dsSchema.StringAttribute{
 				Computed: true,
@@ -71,16 +163,67 @@ func (d *SecretDataSource) Configure(_ context.Context, req datasource.Configure
 	d.client = req.ProviderData.(*APIClient)
 }
 
+const defaultMinVersionTimeout = time.Minute
+
 func (d *SecretDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
 	var data SecretDataModel
 	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
+	ctx, reportUnknown := collectUnknownFields(ctx)
+	defer reportUnknown(&resp.Diagnostics)
 
-	out, err := d.client.GetSecret(data.Namespace.ValueString(), data.Key.ValueString())
+	ctx = withEncryptionContext(ctx, mapFromTF(ctx, &resp.Diagnostics, path.Root("encryption_context"), data.EncryptionContext))
+	checkRequestHeaderNames(&resp.Diagnostics, path.Root("request_headers"), data.RequestHeaders)
+	ctx = withHeaders(ctx, mapFromTF(ctx, &resp.Diagnostics, path.Root("request_headers"), data.RequestHeaders))
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var out *SecretResponse
+	var err error
+	if !data.MinVersion.IsNull() {
+		if data.AsOf.ValueString() != "" || data.ResolveRefs.ValueBool() {
+			resp.Diagnostics.AddAttributeError(path.Root("min_version"), "Conflicting attributes",
+				"min_version cannot be combined with as_of or resolve_refs.")
+			return
+		}
+		wait := defaultMinVersionTimeout
+		if v := data.MinVersionTimeout.ValueString(); v != "" {
+			d, parseErr := time.ParseDuration(v)
+			if parseErr != nil || d <= 0 {
+				resp.Diagnostics.AddAttributeError(path.Root("min_version_timeout"), "Invalid min_version_timeout",
+					fmt.Sprintf("%q is not a positive duration (e.g. \"30s\", \"5m\")", v))
+				return
+			}
+			wait = d
+		}
+		out, err = d.client.GetSecretMinVersion(ctx, data.Namespace.ValueString(), data.Key.ValueString(), int(data.MinVersion.ValueInt64()), wait)
+		if errors.Is(err, errMinVersion) {
+			resp.Diagnostics.AddAttributeError(path.Root("min_version"), "Minimum version not available", err.Error())
+			return
+		}
+	} else if asOf := data.AsOf.ValueString(); asOf != "" {
+		t, parseErr := time.Parse(time.RFC3339, asOf)
+		if parseErr != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("as_of"), "Invalid as_of",
+				fmt.Sprintf("%q is not an RFC3339 timestamp (e.g. \"2024-05-01T14:05:00Z\")", asOf))
+			return
+		}
+		if data.ResolveRefs.ValueBool() {
+			resp.Diagnostics.AddAttributeError(path.Root("as_of"), "Conflicting attributes",
+				"as_of cannot be combined with resolve_refs.")
+			return
+		}
+		out, err = d.client.GetSecretAsOf(ctx, data.Namespace.ValueString(), data.Key.ValueString(), t)
+	} else if data.ResolveRefs.ValueBool() {
+		out, err = d.client.GetSecretResolved(ctx, data.Namespace.ValueString(), data.Key.ValueString())
+	} else {
+		out, err = d.client.GetSecret(ctx, data.Namespace.ValueString(), data.Key.ValueString())
+	}
 	if err != nil {
-		resp.Diagnostics.AddError("Read failed", err.Error())
+		addAPIError(&resp.Diagnostics, "Read failed", err)
 		return
 	}
 	if out == nil {
@@ -89,8 +232,7 @@ func (d *SecretDataSource) Read(ctx context.Context, req datasource.ReadRequest,
 	}
 
 	data.ID = tfTypes.StringValue(fmt.Sprintf("%s/%s", out.Namespace, out.Key))
-	data.Version = tfTypes.Int64Value(int64(out.Version))
-	data.UpdatedAt = tfTypes.StringValue(out.UpdatedAt)
+	data.Version, data.UpdatedAt = versionValues(out)
 	if out.Tags != nil {
 		elems := map[string]attr.Value{}
 		for k, v := range out.Tags {
@@ -101,6 +243,128 @@ func (d *SecretDataSource) Read(ctx context.Context, req datasource.ReadRequest,
 	// Jika API tidak mengembalikan value untuk keamanan, biarkan kosong.
 	if out.Value != "" {
 		data.Value = tfTypes.StringValue(out.Value)
+		data.ValueSHA256 = tfTypes.StringValue(valueSHA256(out.Value))
 	}
+
+	data.Projected = tfTypes.MapNull(tfTypes.StringType)
+	if !data.Project.IsNull() {
+		var paths []string
+		resp.Diagnostics.Append(data.Project.ElementsAs(ctx, &paths, false)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		projected, err := projectValue(out.Value, paths)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("project"), "Projection failed", err.Error())
+			return
+		}
+		projectedVal, diags := tfTypes.MapValueFrom(ctx, tfTypes.StringType, projected)
+		resp.Diagnostics.Append(diags...)
+		data.Projected = projectedVal
+		data.Value = tfTypes.StringNull()
+	}
+
+	var headerNames []string
+	if !data.ResponseHeaderNames.IsNull() {
+		resp.Diagnostics.Append(data.ResponseHeaderNames.ElementsAs(ctx, &headerNames, false)...)
+	}
+	headers := map[string]string{}
+	for _, name := range headerNames {
+		if isAuthHeader(name) {
+			resp.Diagnostics.AddWarning("Response header not exposed",
+				fmt.Sprintf("%q carries credentials and is never copied into state.", name))
+			continue
+		}
+		if vals := out.Headers.Values(name); len(vals) > 0 {
+			headers[name] = strings.Join(vals, ", ")
+		}
+	}
+	responseHeaders, diags := tfTypes.MapValueFrom(ctx, tfTypes.StringType, headers)
+	resp.Diagnostics.Append(diags...)
+	data.ResponseHeaders = responseHeaders
+
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
+
+// authHeaders are response headers that may carry credentials.
+var authHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie", "Token", "X-Api-Key", "X-Auth-Token"}
+
+// checkRequestHeaderNames rejects request_headers entries that would replace
+// the provider's authentication.
+func checkRequestHeaderNames(diags *diag.Diagnostics, p path.Path, m tfTypes.Map) {
+	for k := range m.Elements() {
+		if isAuthHeader(k) {
+			diags.AddAttributeError(p.AtMapKey(k), "Header not allowed",
+				fmt.Sprintf("%q carries credentials and cannot be set per resource; configure authentication on the provider.", k))
+		}
+	}
+}
+
+func isAuthHeader(name string) bool {
+	for _, h := range authHeaders {
+		if strings.EqualFold(h, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// projectValue extracts the fields at paths from the JSON document value.
+// Errors never include the value.
+func projectValue(value string, paths []string) (map[string]string, error) {
+	dec := json.NewDecoder(strings.NewReader(value))
+	dec.UseNumber()
+	var doc interface{}
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("the secret value is not valid JSON")
+	}
+	out := make(map[string]string, len(paths))
+	for _, p := range paths {
+		v, err := lookupJSONPath(doc, p)
+		if err != nil {
+			return nil, err
+		}
+		if str, ok := v.(string); ok {
+			out[p] = str
+			continue
+		}
+		var buf bytes.Buffer
+		enc := json.NewEncoder(&buf)
+		enc.SetEscapeHTML(false)
+		if err := enc.Encode(v); err != nil {
+			return nil, fmt.Errorf("path %q: %w", p, err)
+		}
+		out[p] = strings.TrimSuffix(buf.String(), "\n")
+	}
+	return out, nil
+}
+
+// lookupJSONPath walks a dot-separated path through decoded JSON. Numeric
+// segments index arrays.
+func lookupJSONPath(doc interface{}, p string) (interface{}, error) {
+	if p == "" {
+		return nil, fmt.Errorf("empty path")
+	}
+	segs := strings.Split(p, ".")
+	cur := doc
+	for i, seg := range segs {
+		at := strings.Join(segs[:i+1], ".")
+		switch node := cur.(type) {
+		case map[string]interface{}:
+			v, ok := node[seg]
+			if !ok {
+				return nil, fmt.Errorf("path %q: %q not found", p, at)
+			}
+			cur = v
+		case []interface{}:
+			idx, err := strconv.Atoi(seg)
+			if err != nil || idx < 0 || idx >= len(node) {
+				return nil, fmt.Errorf("path %q: %q is not an index of an array of length %d", p, at, len(node))
+			}
+			cur = node[idx]
+		default:
+			return nil, fmt.Errorf("path %q: %q not found, the value before it is not an object or array", p, at)
+		}
+	}
+	return cur, nil
+}
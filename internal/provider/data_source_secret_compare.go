@@ -0,0 +1,141 @@
+package provider
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	dsSchema "github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	tfTypes "github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var _ datasource.DataSource = &SecretCompareDataSource{}
+
+func NewSecretCompareDataSource() datasource.DataSource {
+	return &SecretCompareDataSource{}
+}
+
+// SecretCompareDataSource tells whether two secrets hold the same value
+// without exposing either value.
+type SecretCompareDataSource struct {
+	client *APIClient
+}
+
+type SecretCompareDataModel struct {
+	ID             tfTypes.String `tfsdk:"id"`
+	Namespace      tfTypes.String `tfsdk:"namespace"`
+	Key            tfTypes.String `tfsdk:"key"`
+	OtherNamespace tfTypes.String `tfsdk:"other_namespace"`
+	OtherKey       tfTypes.String `tfsdk:"other_key"`
+	AllowMissing   tfTypes.Bool   `tfsdk:"allow_missing"`
+	Equal          tfTypes.Bool   `tfsdk:"equal"`
+	Version        tfTypes.Int64  `tfsdk:"version"`
+	OtherVersion   tfTypes.Int64  `tfsdk:"other_version"`
+}
+
+func (d *SecretCompareDataSource) Metadata(_ context.Context, _ datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = "yggdrasil_secret_compare"
+}
+
+func (d *SecretCompareDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = dsSchema.Schema{
+		Description: "Compares the values of two secrets without exposing them, e.g. to assert that a secret was replicated correctly.",
+		Attributes: map[string]dsSchema.Attribute{
+			"namespace": dsSchema.StringAttribute{
+				Required: true,
+			},
+			"key": dsSchema.StringAttribute{
+				Required: true,
+			},
+			"other_namespace": dsSchema.StringAttribute{
+				Required: true,
+			},
+			"other_key": dsSchema.StringAttribute{
+				Required: true,
+			},
+			"allow_missing": dsSchema.BoolAttribute{
+				Optional:    true,
+				Description: "Report `equal = false` instead of failing when either secret does not exist.",
+			},
+			"equal": dsSchema.BoolAttribute{
+				Computed:    true,
+				Description: "Whether both secrets hold exactly the same value.",
+			},
+			"version": dsSchema.Int64Attribute{
+				Computed:    true,
+				Description: "Version of the first secret, or null when it does not exist or the server does not report it.",
+			},
+			"other_version": dsSchema.Int64Attribute{
+				Computed:    true,
+				Description: "Version of the second secret, or null when it does not exist or the server does not report it.",
+			},
+			"id": dsSchema.StringAttribute{
+				Computed: true,
+			},
+		},
+	}
+}
+
+func (d *SecretCompareDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, _ *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	d.client = req.ProviderData.(*APIClient)
+}
+
+func (d *SecretCompareDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data SecretCompareDataModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Read both sides concurrently; when they share a namespace the client's
+	// read deduplication turns this into a single request.
+	sides := [2]struct {
+		ns, key string
+		out     *SecretResponse
+		err     error
+	}{
+		{ns: data.Namespace.ValueString(), key: data.Key.ValueString()},
+		{ns: data.OtherNamespace.ValueString(), key: data.OtherKey.ValueString()},
+	}
+	var wg sync.WaitGroup
+	for i := range sides {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			sides[i].out, sides[i].err = d.client.GetSecret(ctx, sides[i].ns, sides[i].key)
+		}(i)
+	}
+	wg.Wait()
+
+	// Diagnostics never include the values.
+	for _, side := range sides {
+		switch {
+		case side.err != nil:
+			addAPIError(&resp.Diagnostics, "Read failed", fmt.Errorf("%s/%s: %w", side.ns, side.key, side.err))
+		case side.out == nil && !data.AllowMissing.ValueBool():
+			resp.Diagnostics.AddError("Not found", fmt.Sprintf("Secret %s/%s does not exist", side.ns, side.key))
+		}
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	a, b := sides[0].out, sides[1].out
+
+	data.ID = tfTypes.StringValue(fmt.Sprintf("%s/%s=%s/%s",
+		data.Namespace.ValueString(), data.Key.ValueString(), data.OtherNamespace.ValueString(), data.OtherKey.ValueString()))
+	data.Equal = tfTypes.BoolValue(a != nil && b != nil && subtle.ConstantTimeCompare([]byte(a.Value), []byte(b.Value)) == 1)
+	data.Version = tfTypes.Int64Null()
+	if a != nil {
+		data.Version, _ = versionValues(a)
+	}
+	data.OtherVersion = tfTypes.Int64Null()
+	if b != nil {
+		data.OtherVersion, _ = versionValues(b)
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
@@ -0,0 +1,103 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	dsSchema "github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	tfTypes "github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var _ datasource.DataSource = &SecretMetadataDataSource{}
+
+func NewSecretMetadataDataSource() datasource.DataSource {
+	return &SecretMetadataDataSource{}
+}
+
+// SecretMetadataDataSource reads a namespace's version information without
+// downloading its values where the server allows it.
+type SecretMetadataDataSource struct {
+	client *APIClient
+}
+
+type SecretMetadataDataModel struct {
+	ID        tfTypes.String `tfsdk:"id"`
+	Namespace tfTypes.String `tfsdk:"namespace"`
+	Version   tfTypes.Int64  `tfsdk:"version"`
+	UpdatedAt tfTypes.String `tfsdk:"updated_at"`
+	ETag      tfTypes.String `tfsdk:"etag"`
+}
+
+func (d *SecretMetadataDataSource) Metadata(_ context.Context, _ datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = "yggdrasil_secret_metadata"
+}
+
+func (d *SecretMetadataDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = dsSchema.Schema{
+		Description: "Version metadata of a namespace. Uses a HEAD request so secret values are not transferred, falling back to a full read on servers without HEAD support.",
+		Attributes: map[string]dsSchema.Attribute{
+			"namespace": dsSchema.StringAttribute{
+				Required: true,
+			},
+			"version": dsSchema.Int64Attribute{
+				Computed:    true,
+				Description: "Namespace version, or null when the server does not report it.",
+			},
+			"updated_at": dsSchema.StringAttribute{
+				Computed:    true,
+				Description: "Last modification time (RFC3339), or null when the server does not report it.",
+			},
+			"etag": dsSchema.StringAttribute{
+				Computed:    true,
+				Description: "Entity tag of the namespace contents, or null when the server does not report it.",
+			},
+			"id": dsSchema.StringAttribute{
+				Computed: true,
+			},
+		},
+	}
+}
+
+func (d *SecretMetadataDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, _ *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	d.client = req.ProviderData.(*APIClient)
+}
+
+func (d *SecretMetadataDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data SecretMetadataDataModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ns := data.Namespace.ValueString()
+	md, err := d.client.GetNamespaceMetadata(ctx, ns)
+	if err != nil {
+		addAPIError(&resp.Diagnostics, "Read failed", err)
+		return
+	}
+	if md == nil {
+		resp.Diagnostics.AddError("Not found", fmt.Sprintf("Namespace %q does not exist", ns))
+		return
+	}
+
+	data.ID = tfTypes.StringValue(md.Namespace)
+	data.Version = tfTypes.Int64Null()
+	if md.Version > 0 {
+		data.Version = tfTypes.Int64Value(int64(md.Version))
+	}
+	data.UpdatedAt = stringOrNull(md.UpdatedAt)
+	data.ETag = stringOrNull(md.ETag)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// stringOrNull maps "" to a null string.
+func stringOrNull(s string) tfTypes.String {
+	if s == "" {
+		return tfTypes.StringNull()
+	}
+	return tfTypes.StringValue(s)
+}
@@ -0,0 +1,126 @@
+package provider
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	dsSchema "github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	tfTypes "github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var _ datasource.DataSource = &SecretSearchDataSource{}
+
+func NewSecretSearchDataSource() datasource.DataSource {
+	return &SecretSearchDataSource{}
+}
+
+// SecretSearchDataSource finds secrets across namespaces by key and tags using
+// the server's search endpoint. It never reads values.
+type SecretSearchDataSource struct {
+	client *APIClient
+}
+
+type SecretSearchDataModel struct {
+	ID      tfTypes.String `tfsdk:"id"`
+	KeyGlob tfTypes.String `tfsdk:"key_glob"`
+	Tags    tfTypes.Map    `tfsdk:"tags"`
+	Results tfTypes.List   `tfsdk:"results"`
+}
+
+var searchResultAttrTypes = map[string]attr.Type{
+	"namespace":  tfTypes.StringType,
+	"key":        tfTypes.StringType,
+	"version":    tfTypes.Int64Type,
+	"updated_at": tfTypes.StringType,
+}
+
+func (d *SecretSearchDataSource) Metadata(_ context.Context, _ datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = "yggdrasil_secret_search"
+}
+
+func (d *SecretSearchDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = dsSchema.Schema{
+		Description: "Finds secrets in all namespaces by key pattern and tags. Requires a server with the search endpoint. Values are never read. Result pages are fetched one at a time, each retried on `retry_status_codes` like other requests.",
+		Attributes: map[string]dsSchema.Attribute{
+			"key_glob": dsSchema.StringAttribute{
+				Optional:    true,
+				Description: "Glob pattern the key must match, e.g. `db_*`, without `key_prefix`. Matches every key when unset.",
+			},
+			"tags": dsSchema.MapAttribute{
+				ElementType: tfTypes.StringType,
+				Optional:    true,
+				Description: "Tags the secret must carry, all with exactly these values.",
+			},
+			"results": dsSchema.ListNestedAttribute{
+				Computed:    true,
+				Description: "Matching secrets sorted by namespace and key. Secrets in namespaces excluded by `allowed_namespaces` or `denied_namespaces` are omitted.",
+				NestedObject: dsSchema.NestedAttributeObject{
+					Attributes: map[string]dsSchema.Attribute{
+						"namespace":  dsSchema.StringAttribute{Computed: true},
+						"key":        dsSchema.StringAttribute{Computed: true, Description: "Key without `key_prefix`."},
+						"version":    dsSchema.Int64Attribute{Computed: true},
+						"updated_at": dsSchema.StringAttribute{Computed: true},
+					},
+				},
+			},
+			"id": dsSchema.StringAttribute{
+				Computed: true,
+			},
+		},
+	}
+}
+
+func (d *SecretSearchDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, _ *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	d.client = req.ProviderData.(*APIClient)
+}
+
+func (d *SecretSearchDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data SecretSearchDataModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, reportUnknown := collectUnknownFields(ctx)
+	defer reportUnknown(&resp.Diagnostics)
+
+	q := SearchQuery{KeyGlob: data.KeyGlob.ValueString(), Tags: mapFromTF(ctx, &resp.Diagnostics, path.Root("tags"), data.Tags)}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	found, err := d.client.SearchSecrets(ctx, q)
+	if err != nil {
+		addAPIError(&resp.Diagnostics, "Search failed", err)
+		return
+	}
+
+	elems := make([]attr.Value, 0, len(found))
+	for _, r := range found {
+		updatedAt := tfTypes.StringNull()
+		if r.UpdatedAt != "" {
+			updatedAt = tfTypes.StringValue(r.UpdatedAt)
+		}
+		elems = append(elems, tfTypes.ObjectValueMust(searchResultAttrTypes, map[string]attr.Value{
+			"namespace":  tfTypes.StringValue(r.Namespace),
+			"key":        tfTypes.StringValue(r.Key),
+			"version":    tfTypes.Int64Value(int64(r.Version)),
+			"updated_at": updatedAt,
+		}))
+	}
+	results, diags := tfTypes.ListValue(tfTypes.ObjectType{AttrTypes: searchResultAttrTypes}, elems)
+	resp.Diagnostics.Append(diags...)
+
+	// The ID identifies the query, so it is stable across reads.
+	qJSON, _ := json.Marshal(q)
+	sum := sha256.Sum256(qJSON)
+	data.ID = tfTypes.StringValue(hex.EncodeToString(sum[:8]))
+	data.Results = results
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
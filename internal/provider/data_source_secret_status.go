@@ -0,0 +1,118 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	dsSchema "github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	tfTypes "github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var _ datasource.DataSource = &SecretStatusDataSource{}
+
+func NewSecretStatusDataSource() datasource.DataSource {
+	return &SecretStatusDataSource{}
+}
+
+// SecretStatusDataSource reports whether a secret exists, is soft-deleted and
+// still recoverable, or is gone. It never exposes the value.
+type SecretStatusDataSource struct {
+	client *APIClient
+}
+
+type SecretStatusDataModel struct {
+	ID               tfTypes.String `tfsdk:"id"`
+	Namespace        tfTypes.String `tfsdk:"namespace"`
+	Key              tfTypes.String `tfsdk:"key"`
+	Status           tfTypes.String `tfsdk:"status"`
+	DeletedAt        tfTypes.String `tfsdk:"deleted_at"`
+	RecoverableUntil tfTypes.String `tfsdk:"recoverable_until"`
+}
+
+const (
+	secretStatusPresent     = "present"
+	secretStatusSoftDeleted = "soft_deleted"
+	secretStatusAbsent      = "absent"
+)
+
+func (d *SecretStatusDataSource) Metadata(_ context.Context, _ datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = "yggdrasil_secret_status"
+}
+
+func (d *SecretStatusDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = dsSchema.Schema{
+		Description: "Whether a secret exists, has been soft-deleted and can still be recovered, or is gone. Unlike `yggdrasil_secret`, a missing secret is not an error, and the value is never stored in state.",
+		Attributes: map[string]dsSchema.Attribute{
+			"namespace": dsSchema.StringAttribute{
+				Required: true,
+			},
+			"key": dsSchema.StringAttribute{
+				Required: true,
+			},
+			"status": dsSchema.StringAttribute{
+				Computed:    true,
+				Description: "`present`, `soft_deleted` (deleted but recoverable), or `absent` (never existed, purged, or past its recovery window). A server without soft-delete support reports deleted secrets as `absent`.",
+			},
+			"deleted_at": dsSchema.StringAttribute{
+				Computed:    true,
+				Description: "When the secret was soft-deleted (RFC3339), or null unless `status` is `soft_deleted`.",
+			},
+			"recoverable_until": dsSchema.StringAttribute{
+				Computed:    true,
+				Description: "End of the recovery window (RFC3339), or null unless `status` is `soft_deleted`.",
+			},
+			"id": dsSchema.StringAttribute{
+				Computed: true,
+			},
+		},
+	}
+}
+
+func (d *SecretStatusDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, _ *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	d.client = req.ProviderData.(*APIClient)
+}
+
+func (d *SecretStatusDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data SecretStatusDataModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, reportUnknown := collectUnknownFields(ctx)
+	defer reportUnknown(&resp.Diagnostics)
+
+	ns := normalizeNamespace(data.Namespace.ValueString())
+	key := data.Key.ValueString()
+	data.ID = tfTypes.StringValue(fmt.Sprintf("%s/%s", ns, key))
+	data.DeletedAt = tfTypes.StringNull()
+	data.RecoverableUntil = tfTypes.StringNull()
+
+	out, err := d.client.GetSecret(ctx, ns, key)
+	if err != nil {
+		addAPIError(&resp.Diagnostics, "Read failed", err)
+		return
+	}
+	if out != nil {
+		data.Status = tfTypes.StringValue(secretStatusPresent)
+		resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+		return
+	}
+
+	deleted, err := d.client.GetDeletedSecret(ctx, ns, key)
+	if err != nil {
+		addAPIError(&resp.Diagnostics, "Read failed", err)
+		return
+	}
+	if deleted == nil {
+		data.Status = tfTypes.StringValue(secretStatusAbsent)
+	} else {
+		data.Status = tfTypes.StringValue(secretStatusSoftDeleted)
+		data.DeletedAt = stringOrNull(deleted.DeletedAt)
+		data.RecoverableUntil = stringOrNull(deleted.RecoverableUntil)
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
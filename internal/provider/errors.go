@@ -0,0 +1,139 @@
+package provider
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/m34l/terraform-provider-yggdrasil/internal/utils"
+)
+
+// APIError is returned by the client for any non-2xx response.
+type APIError struct {
+	Op         string // operation, e.g. "get secret"
+	StatusCode int
+	RequestID  string
+	Code       string // machine-readable error code from the body, if any
+	Message    string // human-readable message from the body, if any
+	Body       string // redacted response body
+}
+
+func (e *APIError) Error() string {
+	status := fmt.Sprintf("status %d", e.StatusCode)
+	if e.RequestID != "" {
+		status += ", request_id=" + e.RequestID
+	}
+	detail := e.Body
+	if e.Message != "" {
+		detail = e.Message
+		if e.Code != "" {
+			detail = e.Code + ": " + e.Message
+		}
+	}
+	if detail == "" {
+		detail = "empty response body"
+	}
+	return fmt.Sprintf("%s failed (%s): %s", e.Op, status, detail)
+}
+
+// newAPIError builds an APIError from a failed response and its body. The
+// body is redacted before it is stored; code and message are picked from the
+// usual JSON error shapes ({"code","message"} or {"error": ...}).
+func newAPIError(op string, res *http.Response, body []byte) *APIError {
+	e := &APIError{
+		Op:         op,
+		StatusCode: res.StatusCode,
+		RequestID:  requestID(res.Header),
+		Body:       string(utils.RedactBytesChain(body)),
+	}
+	var parsed struct {
+		Code    json.RawMessage `json:"code"`
+		Message string          `json:"message"`
+		Error   json.RawMessage `json:"error"`
+	}
+	if json.Unmarshal(body, &parsed) != nil {
+		return e
+	}
+	e.Code = rawString(parsed.Code)
+	e.Message = parsed.Message
+	if e.Message == "" {
+		// "error" is either a string or a nested {"code","message"} object.
+		var nested struct {
+			Code    json.RawMessage `json:"code"`
+			Message string          `json:"message"`
+		}
+		if json.Unmarshal(parsed.Error, &nested) == nil {
+			e.Message = nested.Message
+			if e.Code == "" {
+				e.Code = rawString(nested.Code)
+			}
+		} else {
+			e.Message = rawString(parsed.Error)
+		}
+	}
+	if e.Message != "" {
+		e.Message = string(utils.RedactBytesChain([]byte(e.Message)))
+	}
+	return e
+}
+
+// rawString returns a JSON string or number as plain text, or "" otherwise.
+func rawString(raw json.RawMessage) string {
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return s
+	}
+	var n json.Number
+	if json.Unmarshal(raw, &n) == nil {
+		return n.String()
+	}
+	return ""
+}
+
+// errorStatus returns the HTTP status of an APIError wrapped by err, or 0.
+func errorStatus(err error) int {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode
+	}
+	return 0
+}
+
+// addAPIError adds err to diags. API errors get a summary and hint for their
+// class; anything else (transport failures, decoding) is reported as-is.
+func addAPIError(diags *diag.Diagnostics, summary string, err error) {
+	detail := err.Error()
+	var protErr *protectedError
+	if errors.As(err, &protErr) {
+		diags.AddError("Secret is protected", detail+
+			"\n\nRemove the protected tag first, or, for a yggdrasil_secret, set force_delete = true, apply, and destroy again.")
+		return
+	}
+	var pinErr *pinMismatchError
+	if errors.As(err, &pinErr) {
+		diags.AddError(summary+": certificate pin mismatch", detail+
+			"\n\nThe server presented a certificate whose key is not pinned. If the server key was rotated on purpose, add its fingerprint to tls_pin_sha256; otherwise the connection may be intercepted.")
+		return
+	}
+	switch status := errorStatus(err); {
+	case status == http.StatusUnauthorized:
+		summary += ": authentication failed"
+		detail += "\n\nCheck that the provider token is valid and has not expired."
+	case status == http.StatusForbidden:
+		summary += ": permission denied"
+		detail += "\n\nThe token is not allowed to perform this operation on the namespace."
+	case status == http.StatusNotFound:
+		summary += ": not found"
+	case status == http.StatusConflict:
+		summary += ": conflict"
+		detail += "\n\nThe object was changed concurrently or is in a conflicting state. Refresh and try again."
+	case status == http.StatusTooManyRequests:
+		summary += ": rate limited"
+		detail += "\n\nThe server kept rejecting requests after retries. Consider lowering -parallelism."
+	case status >= 500:
+		summary += ": server error"
+	}
+	diags.AddError(summary, detail)
+}
@@ -0,0 +1,403 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	resSchema "github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+// fakeServer is a minimal in-memory Yggdrasil REST API. It serves namespace
+// reads (wrapped in "configs", with per-key labels and tags), PUT writes (a
+// null value deletes the key) and per-key tags, and records every request it
+// receives.
+type fakeServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	data     map[string]map[string]string // namespace -> key -> value
+	tags     map[string]map[string]string // "namespace/key" -> tags
+	labels   map[string]map[string]string // "namespace/key" -> labels
+	requests []fakeRequest
+}
+
+type fakeRequest struct {
+	Method string
+	Path   string
+	Body   []byte
+}
+
+func newFakeServer(t *testing.T) *fakeServer {
+	t.Helper()
+	f := &fakeServer{data: map[string]map[string]string{}, tags: map[string]map[string]string{}, labels: map[string]map[string]string{}}
+	f.Server = httptest.NewServer(http.HandlerFunc(f.serve))
+	t.Cleanup(f.Close)
+	return f
+}
+
+func (f *fakeServer) serve(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests = append(f.requests, fakeRequest{Method: r.Method, Path: r.URL.Path, Body: body})
+
+	rest, ok := strings.CutPrefix(r.URL.Path, "/v2/configurations/")
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	switch {
+	case r.Method == http.MethodGet && strings.HasSuffix(rest, "/latest/all"):
+		ns := strings.TrimSuffix(rest, "/latest/all")
+		configs, ok := f.data[ns]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		labels, tags := map[string]map[string]string{}, map[string]map[string]string{}
+		for k := range configs {
+			if l, ok := f.labels[ns+"/"+k]; ok {
+				labels[k] = l
+			}
+			if t, ok := f.tags[ns+"/"+k]; ok {
+				tags[k] = t
+			}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"configs": configs, "labels": labels, "tags": tags})
+	case r.Method == http.MethodGet && strings.HasSuffix(rest, "/tags"):
+		ns, key, _ := strings.Cut(strings.TrimSuffix(rest, "/tags"), "/latest/")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"tags": f.tags[ns+"/"+key]})
+	case r.Method == http.MethodPut:
+		var in struct {
+			Configs map[string]*string `json:"configs"`
+			Labels  map[string]string  `json:"labels"`
+			Tags    map[string]string  `json:"tags"`
+		}
+		if err := json.Unmarshal(body, &in); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if f.data[rest] == nil {
+			f.data[rest] = map[string]string{}
+		}
+		for k, v := range in.Configs {
+			if v == nil {
+				delete(f.data[rest], k)
+				delete(f.tags, rest+"/"+k)
+				delete(f.labels, rest+"/"+k)
+				continue
+			}
+			f.data[rest][k] = *v
+			if in.Tags != nil {
+				f.tags[rest+"/"+k] = in.Tags
+			}
+			if in.Labels != nil {
+				f.labels[rest+"/"+k] = in.Labels
+			}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"version": len(f.requests)})
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// put stores value under ns/key as if it had been written outside Terraform.
+func (f *fakeServer) put(ns, key, value string, tags map[string]string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.data[ns] == nil {
+		f.data[ns] = map[string]string{}
+	}
+	f.data[ns][key] = value
+	if tags != nil {
+		f.tags[ns+"/"+key] = tags
+	}
+}
+
+// setLabels replaces the labels of ns/key as if changed outside Terraform.
+func (f *fakeServer) setLabels(ns, key string, labels map[string]string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.labels[ns+"/"+key] = labels
+}
+
+// keys returns a copy of the keys and values stored in ns.
+func (f *fakeServer) keys(ns string) map[string]string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	out := map[string]string{}
+	for k, v := range f.data[ns] {
+		out[k] = v
+	}
+	return out
+}
+
+// count returns the number of requests with the given method whose path
+// ends in suffix ("" matches every path).
+func (f *fakeServer) count(method, suffix string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	n := 0
+	for _, r := range f.requests {
+		if r.Method == method && strings.HasSuffix(r.Path, suffix) {
+			n++
+		}
+	}
+	return n
+}
+
+// bodies returns the raw bodies of every request with the given method.
+func (f *fakeServer) bodies(method string) [][]byte {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out [][]byte
+	for _, r := range f.requests {
+		if r.Method == method {
+			out = append(out, r.Body)
+		}
+	}
+	return out
+}
+
+// puts returns the decoded "configs" of every PUT received so far.
+func (f *fakeServer) puts(t *testing.T) []map[string]*string {
+	t.Helper()
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out []map[string]*string
+	for _, r := range f.requests {
+		if r.Method != http.MethodPut {
+			continue
+		}
+		var in struct {
+			Configs map[string]*string `json:"configs"`
+		}
+		if err := json.Unmarshal(r.Body, &in); err != nil {
+			t.Fatalf("decoding PUT body %s: %v", r.Body, err)
+		}
+		out = append(out, in.Configs)
+	}
+	return out
+}
+
+func newTestClient(t *testing.T, endpoint string, cfg Config) *APIClient {
+	t.Helper()
+	cfg.Endpoint = endpoint
+	if cfg.Token == "" {
+		cfg.Token = "test-token-0123456789"
+	}
+	c, err := newClient(cfg)
+	if err != nil {
+		t.Fatalf("newClient: %v", err)
+	}
+	return c
+}
+
+func resourceSchema(t *testing.T, r resource.Resource) resSchema.Schema {
+	t.Helper()
+	var resp resource.SchemaResponse
+	r.Schema(context.Background(), resource.SchemaRequest{}, &resp)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("schema: %v", resp.Diagnostics)
+	}
+	return resp.Schema
+}
+
+// tfObject returns a value of s's object type with attrs set and every other
+// attribute null, as Terraform sends an unset optional attribute.
+func tfObject(t *testing.T, s resSchema.Schema, attrs map[string]tftypes.Value) tftypes.Value {
+	t.Helper()
+	typ := s.Type().TerraformType(context.Background()).(tftypes.Object)
+	vals := make(map[string]tftypes.Value, len(typ.AttributeTypes))
+	for name, at := range typ.AttributeTypes {
+		vals[name] = tftypes.NewValue(at, nil)
+	}
+	for name, v := range attrs {
+		if _, ok := vals[name]; !ok {
+			t.Fatalf("schema has no attribute %q", name)
+		}
+		vals[name] = v
+	}
+	return tftypes.NewValue(typ, vals)
+}
+
+// withAttrs returns obj with attrs replaced.
+func withAttrs(t *testing.T, obj tftypes.Value, attrs map[string]tftypes.Value) tftypes.Value {
+	t.Helper()
+	orig := map[string]tftypes.Value{}
+	if err := obj.As(&orig); err != nil {
+		t.Fatalf("withAttrs: %v", err)
+	}
+	// As shares obj's underlying map; copy it so obj is left unchanged.
+	vals := make(map[string]tftypes.Value, len(orig))
+	for name, v := range orig {
+		vals[name] = v
+	}
+	for name, v := range attrs {
+		vals[name] = v
+	}
+	return tftypes.NewValue(obj.Type(), vals)
+}
+
+func tfString(s string) tftypes.Value {
+	return tftypes.NewValue(tftypes.String, s)
+}
+
+func tfBool(b bool) tftypes.Value {
+	return tftypes.NewValue(tftypes.Bool, b)
+}
+
+func tfStringMap(m map[string]string) tftypes.Value {
+	vals := make(map[string]tftypes.Value, len(m))
+	for k, v := range m {
+		vals[k] = tfString(v)
+	}
+	return tftypes.NewValue(tftypes.Map{ElementType: tftypes.String}, vals)
+}
+
+// The test* functions below call a resource method the way the framework
+// does during an apply and fail the test on error diagnostics, unless the
+// caller asks for them with the *Diags variants.
+
+func testCreate(t *testing.T, r resource.Resource, s resSchema.Schema, plan tftypes.Value) tftypes.Value {
+	t.Helper()
+	state, diags := testCreateDiags(r, s, plan)
+	failOnError(t, "create", diags)
+	return state
+}
+
+func testCreateDiags(r resource.Resource, s resSchema.Schema, plan tftypes.Value) (tftypes.Value, diag.Diagnostics) {
+	req := resource.CreateRequest{
+		Config: tfsdk.Config{Schema: s, Raw: plan},
+		Plan:   tfsdk.Plan{Schema: s, Raw: plan},
+	}
+	resp := &resource.CreateResponse{State: tfsdk.State{Schema: s, Raw: tfNull(s)}}
+	r.Create(context.Background(), req, resp)
+	return resp.State.Raw, resp.Diagnostics
+}
+
+func testRead(t *testing.T, r resource.Resource, s resSchema.Schema, state tftypes.Value) tftypes.Value {
+	t.Helper()
+	req := resource.ReadRequest{State: tfsdk.State{Schema: s, Raw: state}}
+	resp := &resource.ReadResponse{State: tfsdk.State{Schema: s, Raw: state}}
+	r.Read(context.Background(), req, resp)
+	failOnError(t, "read", resp.Diagnostics)
+	return resp.State.Raw
+}
+
+func testUpdate(t *testing.T, r resource.Resource, s resSchema.Schema, plan, prior tftypes.Value) tftypes.Value {
+	t.Helper()
+	req := resource.UpdateRequest{
+		Config: tfsdk.Config{Schema: s, Raw: plan},
+		Plan:   tfsdk.Plan{Schema: s, Raw: plan},
+		State:  tfsdk.State{Schema: s, Raw: prior},
+	}
+	resp := &resource.UpdateResponse{State: tfsdk.State{Schema: s, Raw: plan}}
+	r.Update(context.Background(), req, resp)
+	failOnError(t, "update", resp.Diagnostics)
+	return resp.State.Raw
+}
+
+func testDeleteDiags(r resource.Resource, s resSchema.Schema, prior tftypes.Value) diag.Diagnostics {
+	req := resource.DeleteRequest{State: tfsdk.State{Schema: s, Raw: prior}}
+	resp := &resource.DeleteResponse{State: tfsdk.State{Schema: s, Raw: prior}}
+	r.Delete(context.Background(), req, resp)
+	return resp.Diagnostics
+}
+
+func tfNull(s resSchema.Schema) tftypes.Value {
+	return tftypes.NewValue(s.Type().TerraformType(context.Background()), nil)
+}
+
+func failOnError(t *testing.T, op string, diags diag.Diagnostics) {
+	t.Helper()
+	if diags.HasError() {
+		t.Fatalf("%s: %v", op, diags)
+	}
+}
+
+// attrString returns the string attribute name of obj, or "" when it is null.
+func attrString(t *testing.T, obj tftypes.Value, name string) string {
+	t.Helper()
+	vals := map[string]tftypes.Value{}
+	if err := obj.As(&vals); err != nil {
+		t.Fatalf("attrString: %v", err)
+	}
+	var s *string
+	if err := vals[name].As(&s); err != nil {
+		t.Fatalf("attrString(%q): %v", name, err)
+	}
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// testPlan runs ModifyPlan for config against prior, as terraform plan does
+// after the framework has copied config into the proposed new state. Computed
+// attributes that config leaves null are proposed as unknown.
+func testPlan(t *testing.T, r resource.ResourceWithModifyPlan, s resSchema.Schema, config, prior tftypes.Value) tftypes.Value {
+	t.Helper()
+	vals := map[string]tftypes.Value{}
+	if err := config.As(&vals); err != nil {
+		t.Fatalf("testPlan: %v", err)
+	}
+	unknown := map[string]tftypes.Value{}
+	for name, attr := range s.Attributes {
+		if attr.IsComputed() && vals[name].IsNull() {
+			unknown[name] = tftypes.NewValue(vals[name].Type(), tftypes.UnknownValue)
+		}
+	}
+	proposed := withAttrs(t, config, unknown)
+	req := resource.ModifyPlanRequest{
+		Config: tfsdk.Config{Schema: s, Raw: config},
+		Plan:   tfsdk.Plan{Schema: s, Raw: proposed},
+		State:  tfsdk.State{Schema: s, Raw: prior},
+	}
+	resp := &resource.ModifyPlanResponse{Plan: req.Plan}
+	r.ModifyPlan(context.Background(), req, resp)
+	failOnError(t, "plan", resp.Diagnostics)
+	return resp.Plan.Raw
+}
+
+// testImport runs terraform import of id for typeName through an
+// unconfigured provider server and returns the imported state and private
+// state, or the import's error summaries.
+func testImport(t *testing.T, s resSchema.Schema, typeName, id string) (tftypes.Value, []byte, []string) {
+	t.Helper()
+	srv := providerserver.NewProtocol6(New())()
+	resp, err := srv.ImportResourceState(context.Background(), &tfprotov6.ImportResourceStateRequest{TypeName: typeName, ID: id})
+	if err != nil {
+		t.Fatalf("import %q: %v", id, err)
+	}
+	var errs []string
+	for _, d := range resp.Diagnostics {
+		if d.Severity == tfprotov6.DiagnosticSeverityError {
+			errs = append(errs, d.Summary)
+		}
+	}
+	if len(errs) > 0 {
+		return tftypes.Value{}, nil, errs
+	}
+	if len(resp.ImportedResources) != 1 {
+		t.Fatalf("import %q returned %d resources, want 1", id, len(resp.ImportedResources))
+	}
+	imported := resp.ImportedResources[0]
+	state, err := imported.State.Unmarshal(s.Type().TerraformType(context.Background()))
+	if err != nil {
+		t.Fatalf("import %q: decoding state: %v", id, err)
+	}
+	return state, imported.Private, nil
+}
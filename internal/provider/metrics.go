@@ -0,0 +1,167 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// latencyBuckets are the upper bounds, in seconds, of the request duration
+// histogram.
+var latencyBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+type opStatus struct{ op, status string }
+
+type histogram struct {
+	counts []uint64 // per bucket, not cumulative; the last entry is +Inf
+	sum    float64
+	count  uint64
+}
+
+// metrics counts API requests for the /metrics endpoint. It is process-wide
+// so every configured provider instance reports into the same endpoint.
+type metrics struct {
+	mu        sync.Mutex
+	requests  map[opStatus]uint64
+	retries   map[string]uint64
+	durations map[string]*histogram
+}
+
+var providerMetrics = &metrics{
+	requests:  map[opStatus]uint64{},
+	retries:   map[string]uint64{},
+	durations: map[string]*histogram{},
+}
+
+// observe records one HTTP attempt of op. status is 0 when no response was
+// received.
+func (m *metrics) observe(op string, status int, d time.Duration) {
+	label := "error"
+	if status != 0 {
+		label = strconv.Itoa(status)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.requests[opStatus{op, label}]++
+	h := m.durations[op]
+	if h == nil {
+		h = &histogram{counts: make([]uint64, len(latencyBuckets)+1)}
+		m.durations[op] = h
+	}
+	secs := d.Seconds()
+	i := sort.SearchFloat64s(latencyBuckets, secs)
+	h.counts[i]++
+	h.sum += secs
+	h.count++
+}
+
+func (m *metrics) retry(op string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.retries[op]++
+}
+
+// writeTo renders the metrics in the Prometheus text exposition format.
+func (m *metrics) writeTo(w io.Writer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	fmt.Fprintln(w, "# HELP yggdrasil_requests_total API requests by operation and HTTP status (\"error\" when no response was received).")
+	fmt.Fprintln(w, "# TYPE yggdrasil_requests_total counter")
+	keys := make([]opStatus, 0, len(m.requests))
+	for k := range m.requests {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].op != keys[j].op {
+			return keys[i].op < keys[j].op
+		}
+		return keys[i].status < keys[j].status
+	})
+	for _, k := range keys {
+		fmt.Fprintf(w, "yggdrasil_requests_total{operation=%q,status=%q} %d\n", k.op, k.status, m.requests[k])
+	}
+
+	fmt.Fprintln(w, "# HELP yggdrasil_request_retries_total Requests retried because of a retryable status, by operation.")
+	fmt.Fprintln(w, "# TYPE yggdrasil_request_retries_total counter")
+	for _, op := range sortedKeys(m.retries) {
+		fmt.Fprintf(w, "yggdrasil_request_retries_total{operation=%q} %d\n", op, m.retries[op])
+	}
+
+	fmt.Fprintln(w, "# HELP yggdrasil_request_duration_seconds Duration of individual HTTP attempts, by operation.")
+	fmt.Fprintln(w, "# TYPE yggdrasil_request_duration_seconds histogram")
+	for _, op := range sortedKeys(m.durations) {
+		h := m.durations[op]
+		var cum uint64
+		for i, le := range latencyBuckets {
+			cum += h.counts[i]
+			fmt.Fprintf(w, "yggdrasil_request_duration_seconds_bucket{operation=%q,le=%q} %d\n", op, strconv.FormatFloat(le, 'g', -1, 64), cum)
+		}
+		fmt.Fprintf(w, "yggdrasil_request_duration_seconds_bucket{operation=%q,le=\"+Inf\"} %d\n", op, h.count)
+		fmt.Fprintf(w, "yggdrasil_request_duration_seconds_sum{operation=%q} %g\n", op, h.sum)
+		fmt.Fprintf(w, "yggdrasil_request_duration_seconds_count{operation=%q} %d\n", op, h.count)
+	}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	out := make([]string, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
+
+var (
+	metricsMu      sync.Mutex
+	metricsServers = map[string]*http.Server{}
+)
+
+// startMetricsServer serves /metrics on addr unless a server for addr is
+// already running in this process. It returns once the address is bound.
+func startMetricsServer(addr string) error {
+	metricsMu.Lock()
+	defer metricsMu.Unlock()
+	if _, ok := metricsServers[addr]; ok {
+		return nil
+	}
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		providerMetrics.writeTo(w)
+	})
+	srv := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	metricsServers[addr] = srv
+	go func() {
+		if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("[WARN] Metrics server on %s stopped: %v", addr, err)
+		}
+	}()
+	log.Printf("[INFO] Serving metrics on http://%s/metrics", ln.Addr())
+	return nil
+}
+
+// ShutdownMetrics stops all metrics servers, letting in-flight scrapes finish
+// within ctx. It is called when the provider process exits.
+func ShutdownMetrics(ctx context.Context) {
+	metricsMu.Lock()
+	defer metricsMu.Unlock()
+	for addr, srv := range metricsServers {
+		if err := srv.Shutdown(ctx); err != nil {
+			log.Printf("[WARN] Metrics server on %s did not shut down cleanly: %v", addr, err)
+		}
+		delete(metricsServers, addr)
+	}
+}
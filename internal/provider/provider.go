@@ -2,9 +2,19 @@ package provider
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"mime"
 	"os"
+	pathpkg "path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
@@ -20,13 +30,43 @@ func New() provider.Provider {
 type YggdrasilProvider struct{}
 
 type YggdrasilProviderModel struct {
-	Endpoint           tfTypes.String `tfsdk:"endpoint"`
-	Token              tfTypes.String `tfsdk:"token"`
-	NamespaceDefault   tfTypes.String `tfsdk:"namespace_default"`
-	InsecureSkipVerify tfTypes.Bool   `tfsdk:"insecure_skip_verify"`
-	CACertPath         tfTypes.String `tfsdk:"ca_cert_path"`
-	ClientCertPath     tfTypes.String `tfsdk:"client_cert_path"`
-	ClientKeyPath      tfTypes.String `tfsdk:"client_key_path"`
+	Endpoint            tfTypes.String `tfsdk:"endpoint"`
+	ReadEndpoint        tfTypes.String `tfsdk:"read_endpoint"`
+	Token               tfTypes.String `tfsdk:"token"`
+	NamespaceDefault    tfTypes.String `tfsdk:"namespace_default"`
+	InsecureSkipVerify  tfTypes.Bool   `tfsdk:"insecure_skip_verify"`
+	CACertPath          tfTypes.String `tfsdk:"ca_cert_path"`
+	ClientCertPath      tfTypes.String `tfsdk:"client_cert_path"`
+	ClientKeyPath       tfTypes.String `tfsdk:"client_key_path"`
+	RetryStatusCodes    tfTypes.List   `tfsdk:"retry_status_codes"`
+	AuditLogPath        tfTypes.String `tfsdk:"audit_log_path"`
+	RedactPathPatterns  tfTypes.List   `tfsdk:"redact_path_patterns"`
+	RequestTimeout      tfTypes.String `tfsdk:"request_timeout"`
+	AdaptiveTimeout     tfTypes.Bool   `tfsdk:"adaptive_timeout"`
+	AdaptiveTimeoutMin  tfTypes.String `tfsdk:"adaptive_timeout_min"`
+	AdaptiveTimeoutMax  tfTypes.String `tfsdk:"adaptive_timeout_max"`
+	PrewarmConnections  tfTypes.Bool   `tfsdk:"prewarm_connections"`
+	MetricsListenAddr   tfTypes.String `tfsdk:"metrics_listen_addr"`
+	NamespaceCacheTTL   tfTypes.String `tfsdk:"namespace_cache_ttl"`
+	StrictParsing       tfTypes.Bool   `tfsdk:"strict_response_parsing"`
+	ContentType         tfTypes.String `tfsdk:"content_type"`
+	DefaultChangeReason tfTypes.String `tfsdk:"default_change_reason"`
+	AssumeReadFromState tfTypes.Bool   `tfsdk:"assume_read_from_state"`
+	KeyPrefix           tfTypes.String `tfsdk:"key_prefix"`
+	APIVersion          tfTypes.String `tfsdk:"api_version"`
+	RequireAPIVersion   tfTypes.Bool   `tfsdk:"require_explicit_api_version"`
+	VerifyAPIVersion    tfTypes.Bool   `tfsdk:"verify_api_version"`
+	MaxResponseBytes    tfTypes.Int64  `tfsdk:"max_response_bytes"`
+	StreamingThreshold  tfTypes.Int64  `tfsdk:"streaming_threshold_bytes"`
+	ProtectedTag        tfTypes.String `tfsdk:"protected_tag"`
+	AutoCreateNamespace tfTypes.Bool   `tfsdk:"auto_create_namespace"`
+	AllowedNamespaces   tfTypes.List   `tfsdk:"allowed_namespaces"`
+	DeniedNamespaces    tfTypes.List   `tfsdk:"denied_namespaces"`
+	TLSPinSHA256        tfTypes.List   `tfsdk:"tls_pin_sha256"`
+	AuthMethod          tfTypes.String `tfsdk:"auth_method"`
+	TokenFile           tfTypes.String `tfsdk:"token_file"`
+	Protocol            tfTypes.String `tfsdk:"protocol"`
+	GraphQLEndpoint     tfTypes.String `tfsdk:"graphql_endpoint"`
 }
 
 func (p *YggdrasilProvider) Metadata(_ context.Context, _ provider.MetadataRequest, resp *provider.MetadataResponse) {
@@ -36,19 +76,72 @@ func (p *YggdrasilProvider) Metadata(_ context.Context, _ provider.MetadataReque
 func (p *YggdrasilProvider) Schema(_ context.Context, _ provider.SchemaRequest, resp *provider.SchemaResponse) {
 	resp.Schema = schema.Schema{
 		Attributes: map[string]schema.Attribute{
+			"content_type": schema.StringAttribute{
+				Optional:    true,
+				Description: "Media type sent as `Content-Type` on write requests, e.g. `application/vnd.yggdrasil.v2+json`. When set, it is also sent as `Accept`. Defaults to `application/json`.",
+			},
+			"default_change_reason": schema.StringAttribute{
+				Optional:    true,
+				Description: "Change reason sent with every write and delete whose resource does not set `change_reason`, e.g. a CI run URL.",
+			},
+			"denied_namespaces": schema.ListAttribute{
+				ElementType: tfTypes.StringType,
+				Optional:    true,
+				Description: "Glob patterns of namespaces that must never be accessed. Takes precedence over `allowed_namespaces`.",
+			},
 			"endpoint": schema.StringAttribute{
 				Optional:    true,
-				Description: "API endpoint URL. Can also be set via YGG_ENDPOINT environment variable.",
+				Description: "API endpoint URL. Can also be set via YGG_ENDPOINT environment variable. Use `unix:///path/to/socket` to talk plain HTTP over a local Unix domain socket; TLS settings are then ignored and `token` is optional.",
+			},
+			"read_endpoint": schema.StringAttribute{
+				Optional:    true,
+				Description: "Endpoint URL for secret and namespace reads, e.g. a read replica. Writes, deletes and other API calls keep using `endpoint`, and `verify_after_write` always reads from `endpoint`. Uses the same authentication and TLS settings with a separate connection pool. Defaults to `endpoint`.",
 			},
 			"token": schema.StringAttribute{
 				Optional:    true,
 				Sensitive:   true,
 				Description: "API authentication token. Can also be set via YGG_TOKEN environment variable.",
 			},
+			"token_file": schema.StringAttribute{
+				Optional:    true,
+				Description: "Path to a file containing the API token, read once during provider configuration. Surrounding whitespace is ignored.",
+			},
+			"key_prefix": schema.StringAttribute{
+				Optional:    true,
+				Description: "Prefix prepended to every secret key, e.g. `prod.` so that `key = \"db_password\"` targets `prod.db_password`. Resource IDs contain the full prefixed key.",
+			},
+			"strict_response_parsing": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Warn when API responses contain JSON fields this provider version does not recognize, to notice API changes during server upgrades. Responses are still parsed leniently and the fields are ignored. Namespace reads, whose fields are secret keys, are not checked. Defaults to false.",
+			},
+			"streaming_threshold_bytes": schema.Int64Attribute{
+				Optional:    true,
+				Description: "Values and responses of at least this many bytes are streamed: write bodies are JSON-encoded while being sent and read responses are decoded while being received, so a large secret is not held in memory twice. Streamed bodies are not logged. Defaults to 1 MiB.",
+			},
+			"max_response_bytes": schema.Int64Attribute{
+				Optional:    true,
+				Description: "Largest response body the provider will read, in bytes. Larger responses fail the operation instead of being buffered. Defaults to 16 MiB.",
+			},
+			"namespace_cache_ttl": schema.StringAttribute{
+				Optional:    true,
+				Description: "How long a namespace read is reused by other reads of the same namespace, as a Go duration, so that refreshing many secrets in one namespace fetches it once instead of once per secret. Writes made by the provider invalidate it immediately; changes made elsewhere become visible after at most this long. Defaults to `5s`; `0s` disables the cache.",
+			},
 			"namespace_default": schema.StringAttribute{
 				Optional:    true,
 				Description: "Default namespace for secrets.",
 			},
+			"protected_tag": schema.StringAttribute{
+				Optional:    true,
+				Description: "Tag that protects a secret from deletion, as `name=value` (e.g. `protected=true`) or just `name` to match any value. Every delete checks the secret's tags on the server first and fails if the tag is present: destroying a `yggdrasil_secret` (whose tags in state are checked too), keys removed from `yggdrasil_secrets`, deletes in `yggdrasil_transaction`, and the old key of a rename. A `yggdrasil_secret` that sets `force_delete` skips the check.",
+			},
+			"graphql_endpoint": schema.StringAttribute{
+				Optional:    true,
+				Description: "Full URL of the GraphQL API, e.g. `https://ygg.example.com/graphql`. Required when `protocol` is `graphql`.",
+			},
+			"protocol": schema.StringAttribute{
+				Optional:    true,
+				Description: "API used for secret reads, writes and deletes: `rest` (default) or `graphql`. With `graphql`, only single-secret reads, writes and deletes are available (`yggdrasil_secret`, the `yggdrasil_secret` data source, and `yggdrasil_transaction`, which then applies its operations one by one); `resolve_refs`, `as_of` and `generate` are not, and everything else that needs the REST API fails with an error instead of calling `endpoint`.",
+			},
 			"insecure_skip_verify": schema.BoolAttribute{
 				Optional:    true,
 				Description: "Skip TLS certificate verification (development only).",
@@ -57,6 +150,11 @@ func (p *YggdrasilProvider) Schema(_ context.Context, _ provider.SchemaRequest,
 				Optional:    true,
 				Description: "Path to CA certificate file.",
 			},
+			"tls_pin_sha256": schema.ListAttribute{
+				ElementType: tfTypes.StringType,
+				Optional:    true,
+				Description: "Base64 SHA-256 fingerprints of the server certificate's SubjectPublicKeyInfo (the `sha256/` prefix is optional). When set, connections are rejected unless the leaf certificate's key matches one of them, in addition to the usual CA verification. List the current and the next key to rotate without downtime.",
+			},
 			"client_cert_path": schema.StringAttribute{
 				Optional:    true,
 				Description: "Path to client certificate file for mTLS.",
@@ -65,6 +163,73 @@ func (p *YggdrasilProvider) Schema(_ context.Context, _ provider.SchemaRequest,
 				Optional:    true,
 				Description: "Path to client key file for mTLS.",
 			},
+			"allowed_namespaces": schema.ListAttribute{
+				ElementType: tfTypes.StringType,
+				Optional:    true,
+				Description: "Glob patterns (e.g. `team-a-*`) of the namespaces resources and data sources may access. Any other namespace fails before a request is sent. Unset allows all namespaces not denied.",
+			},
+			"api_version": schema.StringAttribute{
+				Optional:    true,
+				Description: "API version path segment, e.g. `v2`. Defaults to `v2` unless `require_explicit_api_version` is set.",
+			},
+			"assume_read_from_state": schema.BoolAttribute{
+				Optional:    true,
+				Description: "When refreshing a `yggdrasil_secret` fails with 403, keep the existing state and warn instead of failing. For write-only tokens; drift cannot be detected while this applies.",
+			},
+			"auto_create_namespace": schema.BoolAttribute{
+				Optional:    true,
+				Description: "When a secret write fails with 404 because its namespace does not exist, create the namespace and retry the write once. Defaults to false.",
+			},
+			"auth_method": schema.StringAttribute{
+				Optional:    true,
+				Description: "Authentication method: `token` (`token` or YGG_TOKEN) or `token_file` (`token_file`). `oauth` and `sigv4` are reserved and currently rejected. When unset, `token`/YGG_TOKEN is used if present, otherwise `token_file`; configuring both is an error.",
+			},
+			"audit_log_path": schema.StringAttribute{
+				Optional:    true,
+				Description: "Path to a file that receives one JSON line per successful create, update or delete. Secret values are never written.",
+			},
+			"metrics_listen_addr": schema.StringAttribute{
+				Optional:    true,
+				Description: "Address such as `127.0.0.1:9464` on which to serve Prometheus metrics at `/metrics` while the provider runs: request counts by operation and status, retries, and a request duration histogram. The endpoint has no authentication, so bind it to a loopback or otherwise trusted interface. It is stopped when the provider process exits.",
+			},
+			"prewarm_connections": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Open a keep-alive connection to the endpoint during provider configuration so the first operation does not pay for connection setup.",
+			},
+			"redact_path_patterns": schema.ListAttribute{
+				ElementType: tfTypes.StringType,
+				Optional:    true,
+				Description: "Regular expressions matched against individual URL path segments; matching segments are masked in logs. Segments following `token`, `secret`, `password` and similar are always masked.",
+			},
+			"adaptive_timeout": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Derive each operation's deadline from recently observed latency instead of using `request_timeout` for all of them. Reads, writes, deletes and other operation types are tracked separately; the deadline is four times the median of the last 20 successful operations of that type, between `adaptive_timeout_min` and `adaptive_timeout_max`. Operations that fail, get an error response or run out of time are not counted. Until three operations of a type have succeeded, `request_timeout` applies.",
+			},
+			"adaptive_timeout_min": schema.StringAttribute{
+				Optional:    true,
+				Description: "Lower bound for deadlines chosen by `adaptive_timeout`, as a Go duration. Defaults to `5s`.",
+			},
+			"adaptive_timeout_max": schema.StringAttribute{
+				Optional:    true,
+				Description: "Upper bound for deadlines chosen by `adaptive_timeout`, as a Go duration. It may exceed `request_timeout`, so that operation types the server is consistently slow at are given more time than `request_timeout` allows. Defaults to `request_timeout`.",
+			},
+			"request_timeout": schema.StringAttribute{
+				Optional:    true,
+				Description: "Deadline for a single API operation including its retries, as a Go duration such as `45s` or `2m`. Defaults to `30s`. Cancellation by Terraform (e.g. interrupting an apply) always takes effect first. Connection setup and the TLS handshake are additionally capped at 10s each. The `timeouts` block of `yggdrasil_secret` replaces it for the operations it sets.",
+			},
+			"verify_api_version": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Check during provider configuration that the server serves `api_version`, so a provider pointed at a server for another API version fails immediately instead of deep in an apply. The check reads the server's unversioned `/info` endpoint and fails configuration when the advertised API versions do not include `api_version`; servers without that endpoint are not checked. Off by default, as it sends a request every time the provider is configured.",
+			},
+			"require_explicit_api_version": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Fail configuration when `api_version` is not set instead of defaulting to `v2`. Guards against misrouting in mixed-version fleets.",
+			},
+			"retry_status_codes": schema.ListAttribute{
+				ElementType: tfTypes.Int64Type,
+				Optional:    true,
+				Description: "HTTP status codes that trigger a retry. Overrides the default set (429, 500, 502, 503, 504); an empty list disables retries.",
+			},
 		},
 	}
 }
@@ -77,26 +242,222 @@ func (p *YggdrasilProvider) Configure(ctx context.Context, req provider.Configur
 	}
 
 	endpoint := getStringValue(data.Endpoint, os.Getenv("YGG_ENDPOINT"))
-	token := getStringValue(data.Token, os.Getenv("YGG_TOKEN"))
 
 	if endpoint == "" {
 		resp.Diagnostics.AddError("Missing endpoint", "Endpoint must be set via config or YGG_ENDPOINT")
 		return
 	}
-	if token == "" {
-		resp.Diagnostics.AddError("Missing token", "Token must be set via config or YGG_TOKEN")
+
+	// A local socket is protected by file permissions, so a token is optional.
+	token := resolveToken(data, strings.HasPrefix(endpoint, unixScheme), &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	apiVersion := data.APIVersion.ValueString()
+	if apiVersion == "" && data.RequireAPIVersion.ValueBool() {
+		resp.Diagnostics.AddAttributeError(path.Root("api_version"), "Missing api_version",
+			"require_explicit_api_version is set, so api_version must be configured (e.g. \"v2\").")
+		return
+	}
+
+	var retryStatusCodes []int
+	if !data.RetryStatusCodes.IsNull() && !data.RetryStatusCodes.IsUnknown() {
+		var codes []int64
+		resp.Diagnostics.Append(data.RetryStatusCodes.ElementsAs(ctx, &codes, false)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		retryStatusCodes = make([]int, 0, len(codes))
+		for _, code := range codes {
+			if code < 100 || code > 599 {
+				resp.Diagnostics.AddAttributeError(path.Root("retry_status_codes"), "Invalid retry status code",
+					fmt.Sprintf("%d is not a valid HTTP status code (expected 100-599)", code))
+				continue
+			}
+			retryStatusCodes = append(retryStatusCodes, int(code))
+		}
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	var redactPathPatterns []*regexp.Regexp
+	if !data.RedactPathPatterns.IsNull() && !data.RedactPathPatterns.IsUnknown() {
+		var patterns []string
+		resp.Diagnostics.Append(data.RedactPathPatterns.ElementsAs(ctx, &patterns, false)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		for _, p := range patterns {
+			rx, err := regexp.Compile(p)
+			if err != nil {
+				resp.Diagnostics.AddAttributeError(path.Root("redact_path_patterns"), "Invalid redaction pattern",
+					fmt.Sprintf("%q is not a valid regular expression: %s", p, err))
+				continue
+			}
+			redactPathPatterns = append(redactPathPatterns, rx)
+		}
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	protocol := data.Protocol.ValueString()
+	switch protocol {
+	case "", protocolREST:
+	case protocolGraphQL:
+		if data.GraphQLEndpoint.ValueString() == "" {
+			resp.Diagnostics.AddAttributeError(path.Root("graphql_endpoint"), "Missing graphql_endpoint",
+				"protocol \"graphql\" requires graphql_endpoint to be set.")
+			return
+		}
+	default:
+		resp.Diagnostics.AddAttributeError(path.Root("protocol"), "Invalid protocol",
+			fmt.Sprintf("protocol must be %q or %q, got %q", protocolREST, protocolGraphQL, protocol))
+		return
+	}
+
+	allowedNamespaces := namespacePatterns(ctx, data.AllowedNamespaces, path.Root("allowed_namespaces"), &resp.Diagnostics)
+	deniedNamespaces := namespacePatterns(ctx, data.DeniedNamespaces, path.Root("denied_namespaces"), &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
 		return
 	}
 
+	var tlsPins []string
+	if !data.TLSPinSHA256.IsNull() && !data.TLSPinSHA256.IsUnknown() {
+		var pins []string
+		resp.Diagnostics.Append(data.TLSPinSHA256.ElementsAs(ctx, &pins, false)...)
+		for _, p := range pins {
+			pin, err := parsePin(p)
+			if err != nil {
+				resp.Diagnostics.AddAttributeError(path.Root("tls_pin_sha256"), "Invalid certificate pin", err.Error())
+				continue
+			}
+			tlsPins = append(tlsPins, pin)
+		}
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		if len(tlsPins) == 0 {
+			resp.Diagnostics.AddAttributeError(path.Root("tls_pin_sha256"), "Empty tls_pin_sha256",
+				"tls_pin_sha256 is set but lists no fingerprints, so every connection would be rejected.")
+			return
+		}
+	}
+
+	contentType := data.ContentType.ValueString()
+	if contentType != "" {
+		mediaType, _, err := mime.ParseMediaType(contentType)
+		if err != nil || !strings.Contains(mediaType, "/") {
+			resp.Diagnostics.AddAttributeError(path.Root("content_type"), "Invalid content type",
+				fmt.Sprintf("%q is not a valid media type (expected type/subtype, e.g. \"application/json\")", contentType))
+			return
+		}
+	}
+
+	if !data.MaxResponseBytes.IsNull() && data.MaxResponseBytes.ValueInt64() <= 0 {
+		resp.Diagnostics.AddAttributeError(path.Root("max_response_bytes"), "Invalid max_response_bytes",
+			"max_response_bytes must be a positive number of bytes.")
+		return
+	}
+	if v := data.ProtectedTag.ValueString(); !data.ProtectedTag.IsNull() && (v == "" || strings.HasPrefix(v, "=")) {
+		resp.Diagnostics.AddAttributeError(path.Root("protected_tag"), "Invalid protected_tag",
+			fmt.Sprintf("protected_tag must be a tag name or name=value, got %q", v))
+		return
+	}
+	if !data.StreamingThreshold.IsNull() && data.StreamingThreshold.ValueInt64() <= 0 {
+		resp.Diagnostics.AddAttributeError(path.Root("streaming_threshold_bytes"), "Invalid streaming_threshold_bytes",
+			"streaming_threshold_bytes must be a positive number of bytes.")
+		return
+	}
+
+	var requestTimeout time.Duration
+	if v := data.RequestTimeout.ValueString(); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			resp.Diagnostics.AddAttributeError(path.Root("request_timeout"), "Invalid request timeout",
+				fmt.Sprintf("%q is not a positive duration (e.g. \"30s\", \"2m\")", v))
+			return
+		}
+		requestTimeout = d
+	}
+
+	var namespaceCacheTTL *time.Duration
+	if v := data.NamespaceCacheTTL.ValueString(); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d < 0 {
+			resp.Diagnostics.AddAttributeError(path.Root("namespace_cache_ttl"), "Invalid namespace cache TTL",
+				fmt.Sprintf("%q is not a duration (e.g. \"5s\", or \"0s\" to disable)", v))
+			return
+		}
+		namespaceCacheTTL = &d
+	}
+
+	var adaptiveTimeoutMin time.Duration
+	if v := data.AdaptiveTimeoutMin.ValueString(); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			resp.Diagnostics.AddAttributeError(path.Root("adaptive_timeout_min"), "Invalid adaptive timeout minimum",
+				fmt.Sprintf("%q is not a positive duration (e.g. \"5s\")", v))
+			return
+		}
+		adaptiveTimeoutMin = d
+	}
+
+	var adaptiveTimeoutMax time.Duration
+	if v := data.AdaptiveTimeoutMax.ValueString(); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			resp.Diagnostics.AddAttributeError(path.Root("adaptive_timeout_max"), "Invalid adaptive timeout maximum",
+				fmt.Sprintf("%q is not a positive duration (e.g. \"2m\")", v))
+			return
+		}
+		adaptiveTimeoutMax = d
+	}
+
 	cfg := Config{
-		Endpoint:           endpoint,
-		Token:              token,
-		NamespaceDefault:   data.NamespaceDefault.ValueString(),
-		InsecureSkipVerify: data.InsecureSkipVerify.ValueBool(),
-		CACertPath:         data.CACertPath.ValueString(),
-		ClientCertPath:     data.ClientCertPath.ValueString(),
-		ClientKeyPath:      data.ClientKeyPath.ValueString(),
-		APIVersion:         "v2", // hardcoded to v2
+		Endpoint:            endpoint,
+		ReadEndpoint:        data.ReadEndpoint.ValueString(),
+		Token:               token,
+		NamespaceDefault:    data.NamespaceDefault.ValueString(),
+		InsecureSkipVerify:  data.InsecureSkipVerify.ValueBool(),
+		CACertPath:          data.CACertPath.ValueString(),
+		ClientCertPath:      data.ClientCertPath.ValueString(),
+		ClientKeyPath:       data.ClientKeyPath.ValueString(),
+		APIVersion:          apiVersion,
+		RetryStatusCodes:    retryStatusCodes,
+		AuditLogPath:        data.AuditLogPath.ValueString(),
+		RedactPathPatterns:  redactPathPatterns,
+		RequestTimeout:      requestTimeout,
+		AdaptiveTimeout:     data.AdaptiveTimeout.ValueBool(),
+		AdaptiveTimeoutMin:  adaptiveTimeoutMin,
+		AdaptiveTimeoutMax:  adaptiveTimeoutMax,
+		ContentType:         contentType,
+		DefaultChangeReason: data.DefaultChangeReason.ValueString(),
+		KeyPrefix:           data.KeyPrefix.ValueString(),
+		MaxResponseBytes:    data.MaxResponseBytes.ValueInt64(),
+		StreamingThreshold:  data.StreamingThreshold.ValueInt64(),
+		ProtectedTag:        data.ProtectedTag.ValueString(),
+
+		AssumeReadFromState: data.AssumeReadFromState.ValueBool(),
+		AutoCreateNamespace: data.AutoCreateNamespace.ValueBool(),
+		AllowedNamespaces:   allowedNamespaces,
+		DeniedNamespaces:    deniedNamespaces,
+		TLSPinSHA256:        tlsPins,
+		Protocol:            protocol,
+		GraphQLEndpoint:     data.GraphQLEndpoint.ValueString(),
+		MetricsListenAddr:   data.MetricsListenAddr.ValueString(),
+		NamespaceCacheTTL:   namespaceCacheTTL,
+		StrictParsing:       data.StrictParsing.ValueBool(),
+	}
+
+	if cfg.MetricsListenAddr != "" {
+		if err := startMetricsServer(cfg.MetricsListenAddr); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("metrics_listen_addr"), "Cannot serve metrics",
+				fmt.Sprintf("Listening on %s failed: %s", cfg.MetricsListenAddr, err))
+			return
+		}
 	}
 
 	client, err := newClient(cfg)
@@ -105,6 +466,30 @@ func (p *YggdrasilProvider) Configure(ctx context.Context, req provider.Configur
 		return
 	}
 
+	// The version check reads the REST info endpoint.
+	if data.VerifyAPIVersion.ValueBool() && protocol != protocolGraphQL {
+		var mismatch *apiVersionMismatchError
+		err := client.CheckAPIVersion(ctx)
+		switch {
+		case errors.As(err, &mismatch):
+			resp.Diagnostics.AddAttributeError(path.Root("api_version"), "API version not served by the server",
+				fmt.Sprintf("The provider is configured for API %s, but %s advertises %s. Set api_version to a version the server supports, "+
+					"or point the provider at a server for %s. Unset verify_api_version to bypass this check.",
+					mismatch.Configured, endpoint, strings.Join(mismatch.Supported, ", "), mismatch.Configured))
+			return
+		case err != nil:
+			resp.Diagnostics.AddWarning("API version check failed",
+				fmt.Sprintf("Could not read the API versions served by %s, so api_version was not verified: %s", endpoint, err))
+		}
+	}
+
+	if data.PrewarmConnections.ValueBool() {
+		if err := client.Prewarm(ctx); err != nil {
+			resp.Diagnostics.AddWarning("Connection prewarm failed",
+				fmt.Sprintf("Could not open a connection to %s ahead of time; operations will connect on demand: %s", endpoint, err))
+		}
+	}
+
 	resp.DataSourceData = client
 	resp.ResourceData = client
 }
@@ -112,13 +497,107 @@ func (p *YggdrasilProvider) Configure(ctx context.Context, req provider.Configur
 func (p *YggdrasilProvider) Resources(_ context.Context) []func() resource.Resource {
 	return []func() resource.Resource{
 		NewSecretResource,
+		NewAPIRequestResource,
+		NewTransactionResource,
+		NewSecretAliasResource,
+		NewSecretsResource,
 	}
 }
 
 func (p *YggdrasilProvider) DataSources(_ context.Context) []func() datasource.DataSource {
 	return []func() datasource.DataSource{
 		NewSecretDataSource,
+		NewLayeredSecretDataSource,
+		NewSecretMetadataDataSource,
+		NewSecretCompareDataSource,
+		NewNamespaceDataSource,
+		NewSecretSearchDataSource,
+		NewSecretStatusDataSource,
+	}
+}
+
+const (
+	authMethodToken     = "token"
+	authMethodTokenFile = "token_file"
+	authMethodOAuth     = "oauth"
+	authMethodSigV4     = "sigv4"
+)
+
+// authPrecedence explains auto-detection in diagnostics.
+const authPrecedence = "Without auth_method, the provider uses `token` (or YGG_TOKEN) when set, otherwise `token_file`."
+
+// resolveToken picks the credentials for the configured or detected
+// auth_method and checks that exactly the attributes it needs are set.
+func resolveToken(data YggdrasilProviderModel, tokenOptional bool, diags *diag.Diagnostics) string {
+	token := getStringValue(data.Token, os.Getenv("YGG_TOKEN"))
+	tokenFile := data.TokenFile.ValueString()
+
+	method := data.AuthMethod.ValueString()
+	switch method {
+	case "":
+		if data.Token.ValueString() != "" && tokenFile != "" {
+			diags.AddAttributeError(path.Root("auth_method"), "Ambiguous authentication",
+				"Both token and token_file are configured. Remove one or set auth_method to choose.")
+			return ""
+		}
+		method = authMethodToken
+		if token == "" && tokenFile != "" {
+			method = authMethodTokenFile
+		}
+	case authMethodToken, authMethodTokenFile:
+		if (method == authMethodToken && tokenFile != "") || (method == authMethodTokenFile && data.Token.ValueString() != "") {
+			diags.AddAttributeError(path.Root("auth_method"), "Ambiguous authentication",
+				fmt.Sprintf("auth_method is %q, but both token and token_file are configured. Remove the one that is not used.", method))
+			return ""
+		}
+	case authMethodOAuth, authMethodSigV4:
+		diags.AddAttributeError(path.Root("auth_method"), "Unsupported auth_method",
+			fmt.Sprintf("auth_method %q is not supported by this provider version. Use %q or %q.", method, authMethodToken, authMethodTokenFile))
+		return ""
+	default:
+		diags.AddAttributeError(path.Root("auth_method"), "Invalid auth_method",
+			fmt.Sprintf("auth_method must be %q or %q, got %q.", authMethodToken, authMethodTokenFile, method))
+		return ""
+	}
+
+	if method == authMethodTokenFile {
+		if tokenFile == "" {
+			diags.AddAttributeError(path.Root("token_file"), "Missing token_file", "auth_method \"token_file\" requires token_file to be set.")
+			return ""
+		}
+		b, err := os.ReadFile(filepath.Clean(tokenFile))
+		if err != nil {
+			diags.AddAttributeError(path.Root("token_file"), "Unreadable token_file", err.Error())
+			return ""
+		}
+		token = strings.TrimSpace(string(b))
+		if token == "" {
+			diags.AddAttributeError(path.Root("token_file"), "Empty token_file", fmt.Sprintf("%s contains no token.", tokenFile))
+		}
+		return token
+	}
+
+	if token == "" && !tokenOptional {
+		diags.AddError("Missing token", "Token must be set via config or YGG_TOKEN, or use token_file. "+authPrecedence)
+	}
+	return token
+}
+
+// namespacePatterns reads a list of namespace glob patterns, reporting
+// malformed patterns against attr.
+func namespacePatterns(ctx context.Context, list tfTypes.List, attr path.Path, diags *diag.Diagnostics) []string {
+	if list.IsNull() || list.IsUnknown() {
+		return nil
+	}
+	var patterns []string
+	diags.Append(list.ElementsAs(ctx, &patterns, false)...)
+	for _, p := range patterns {
+		if _, err := pathpkg.Match(p, ""); err != nil {
+			diags.AddAttributeError(attr, "Invalid namespace pattern",
+				fmt.Sprintf("%q is not a valid glob pattern: %s", p, err))
+		}
 	}
+	return patterns
 }
 
 func getStringValue(tfVal tfTypes.String, envVal string) string {
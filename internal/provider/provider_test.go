@@ -0,0 +1,65 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/provider"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+// testConfigure configures the provider with attrs set and every other
+// attribute null.
+func testConfigure(t *testing.T, attrs map[string]tftypes.Value) diag.Diagnostics {
+	t.Helper()
+	p := New()
+	var sresp provider.SchemaResponse
+	p.Schema(context.Background(), provider.SchemaRequest{}, &sresp)
+	typ := sresp.Schema.Type().TerraformType(context.Background()).(tftypes.Object)
+	vals := map[string]tftypes.Value{}
+	for name, at := range typ.AttributeTypes {
+		vals[name] = tftypes.NewValue(at, nil)
+	}
+	for name, v := range attrs {
+		vals[name] = v
+	}
+	resp := &provider.ConfigureResponse{}
+	p.Configure(context.Background(), provider.ConfigureRequest{
+		Config: tfsdk.Config{Schema: sresp.Schema, Raw: tftypes.NewValue(typ, vals)},
+	}, resp)
+	return resp.Diagnostics
+}
+
+func TestProviderChecksAPIVersionOnlyWhenAskedTo(t *testing.T) {
+	var infos atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/info" {
+			infos.Add(1)
+		}
+		_, _ = w.Write([]byte(`{"api_versions":["v1"]}`))
+	}))
+	defer srv.Close()
+	attrs := map[string]tftypes.Value{
+		"endpoint": tfString(srv.URL),
+		"token":    tfString("test-token-0123456789"),
+	}
+
+	failOnError(t, "configure", testConfigure(t, attrs))
+	if n := infos.Load(); n != 0 {
+		t.Errorf("configure sent %d version checks without verify_api_version, want none", n)
+	}
+
+	attrs["verify_api_version"] = tfBool(true)
+	diags := testConfigure(t, attrs)
+	if !diags.HasError() || diags[0].Summary() != "API version not served by the server" {
+		t.Errorf("diagnostics = %v, want an API version mismatch", diags)
+	}
+	if n := infos.Load(); n != 1 {
+		t.Errorf("configure sent %d version checks with verify_api_version, want 1", n)
+	}
+}
@@ -0,0 +1,135 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	resSchema "github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	stringplanmodifier "github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	tfTypes "github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var _ resource.Resource = &APIRequestResource{}
+
+func NewAPIRequestResource() resource.Resource {
+	return &APIRequestResource{}
+}
+
+// APIRequestResource is an escape hatch for endpoints without a first-class
+// resource. The request is issued once on create; changing any input issues it again.
+type APIRequestResource struct {
+	client *APIClient
+}
+
+type APIRequestResourceModel struct {
+	ID           tfTypes.String `tfsdk:"id"`
+	Method       tfTypes.String `tfsdk:"method"`
+	Path         tfTypes.String `tfsdk:"path"`
+	RequestBody  tfTypes.String `tfsdk:"request_body"`
+	StatusCode   tfTypes.Int64  `tfsdk:"status_code"`
+	ResponseBody tfTypes.String `tfsdk:"response_body"`
+}
+
+func (r *APIRequestResource) Metadata(_ context.Context, _ resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = "yggdrasil_api_request"
+}
+
+func (r *APIRequestResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	replace := []planmodifier.String{stringplanmodifier.RequiresReplace()}
+	resp.Schema = resSchema.Schema{
+		Attributes: map[string]resSchema.Attribute{
+			"id": resSchema.StringAttribute{
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"method": resSchema.StringAttribute{
+				Required:      true,
+				Description:   "HTTP method, e.g. `POST`. Only `GET`, `HEAD`, `PUT` and `DELETE` requests are retried on `retry_status_codes`; others are sent once.",
+				PlanModifiers: replace,
+			},
+			"path": resSchema.StringAttribute{
+				Required:      true,
+				Description:   "Path relative to the provider endpoint, including the API version, e.g. `/v2/rotate/team/db_password`. While the provider sets `allowed_namespaces` or `denied_namespaces`, only `/v2/configurations/<namespace>/...` and `/v2/namespaces/<namespace>` paths are accepted, and their namespace is checked like any other.",
+				PlanModifiers: replace,
+			},
+			"request_body": resSchema.StringAttribute{
+				Optional:      true,
+				Sensitive:     true,
+				Description:   "Request body, sent as `application/json`.",
+				PlanModifiers: replace,
+			},
+			"status_code": resSchema.Int64Attribute{
+				Computed: true,
+			},
+			"response_body": resSchema.StringAttribute{
+				Computed:    true,
+				Sensitive:   true,
+				Description: "Raw response body. Use `jsondecode()` to parse JSON responses.",
+			},
+		},
+	}
+}
+
+func (r *APIRequestResource) Configure(_ context.Context, req resource.ConfigureRequest, _ *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	r.client = req.ProviderData.(*APIClient)
+}
+
+func (r *APIRequestResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan APIRequestResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	method := strings.ToUpper(plan.Method.ValueString())
+	var body []byte
+	if !plan.RequestBody.IsNull() {
+		body = []byte(plan.RequestBody.ValueString())
+	}
+
+	status, respBody, err := r.client.RawRequest(ctx, method, plan.Path.ValueString(), body)
+	if err != nil {
+		addAPIError(&resp.Diagnostics, "API request failed", err)
+		return
+	}
+
+	plan.ID = tfTypes.StringValue(fmt.Sprintf("%s %s", method, plan.Path.ValueString()))
+	plan.StatusCode = tfTypes.Int64Value(int64(status))
+	plan.ResponseBody = tfTypes.StringValue(string(respBody))
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *APIRequestResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	// The request is a one-shot operation; there is nothing to refresh.
+	var state APIRequestResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+func (r *APIRequestResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	// All inputs require replacement, so only computed values can reach here.
+	var plan, state APIRequestResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	plan.StatusCode = state.StatusCode
+	plan.ResponseBody = state.ResponseBody
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *APIRequestResource) Delete(_ context.Context, _ resource.DeleteRequest, _ *resource.DeleteResponse) {
+	// Nothing to undo on the server; the resource is simply dropped from state.
+}
@@ -2,18 +2,41 @@ package provider
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"log"
+	"maps"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
 
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	resSchema "github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/boolplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/mapplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	stringplanmodifier "github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
 	tfTypes "github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/m34l/terraform-provider-yggdrasil/internal/utils"
 )
 
 var _ resource.Resource = &SecretResource{}
 var _ resource.ResourceWithImportState = &SecretResource{}
+var _ resource.ResourceWithValidateConfig = &SecretResource{}
+var _ resource.ResourceWithModifyPlan = &SecretResource{}
+
+// importVersionKey is the private state key holding the version requested via
+// "namespace/key@version" until the first refresh after import consumes it.
+const importVersionKey = "import_version"
 
 func NewSecretResource() resource.Resource {
 	return &SecretResource{}
@@ -29,8 +52,43 @@ type SecretResourceModel struct {
 	Key       tfTypes.String `tfsdk:"key"`
 	Value     tfTypes.String `tfsdk:"value"` // Sensitive
 	Tags      tfTypes.Map    `tfsdk:"tags"`
+	Labels    tfTypes.Map    `tfsdk:"labels"`
 	Version   tfTypes.Int64  `tfsdk:"version"`
 	UpdatedAt tfTypes.String `tfsdk:"updated_at"`
+
+	ValueSHA256 tfTypes.String `tfsdk:"value_sha256"`
+
+	TrimTrailingNewline tfTypes.Bool   `tfsdk:"trim_trailing_newline"`
+	TrimValue           tfTypes.Bool   `tfsdk:"trim_value"`
+	RejectBOM           tfTypes.Bool   `tfsdk:"reject_bom"`
+	RenameFrom          tfTypes.String `tfsdk:"rename_from"`
+	ChangeReason        tfTypes.String `tfsdk:"change_reason"`
+	WriteToFile         tfTypes.String `tfsdk:"write_to_file"`
+	EncryptionContext   tfTypes.Map    `tfsdk:"encryption_context"`
+	VerifyAfterWrite    tfTypes.Bool   `tfsdk:"verify_after_write"`
+	Generate            tfTypes.Bool   `tfsdk:"generate"`
+
+	SkipIfNamespaceMissing tfTypes.Bool `tfsdk:"skip_if_namespace_missing"`
+	Skipped                tfTypes.Bool `tfsdk:"skipped"`
+
+	InsecureSkipVerify tfTypes.Bool   `tfsdk:"insecure_skip_verify"`
+	CACertPath         tfTypes.String `tfsdk:"ca_cert_path"`
+
+	AcquireLock tfTypes.Bool `tfsdk:"acquire_lock"`
+	ForceDelete tfTypes.Bool `tfsdk:"force_delete"`
+	SoftDelete  tfTypes.Bool `tfsdk:"soft_delete"`
+
+	Manifest tfTypes.Object `tfsdk:"manifest"`
+
+	ValueTransform tfTypes.List `tfsdk:"value_transform"`
+
+	InheritNamespaceTags tfTypes.Bool `tfsdk:"inherit_namespace_tags"`
+	EffectiveTags        tfTypes.Map  `tfsdk:"effective_tags"`
+
+	RequestHeaders      tfTypes.Map `tfsdk:"request_headers"`
+	WriteRequestHeaders tfTypes.Map `tfsdk:"write_request_headers"`
+
+	Timeouts tfTypes.Object `tfsdk:"timeouts"`
 }
 
 func (r *SecretResource) Metadata(_ context.Context, _ resource.MetadataRequest, resp *resource.MetadataResponse) {
@@ -47,19 +105,141 @@ func (r *SecretResource) Schema(_ context.Context, _ resource.SchemaRequest, res
 				},
 			},
 			"namespace": resSchema.StringAttribute{
-				Required: true,
+				Required:    true,
+				Description: "Namespace of the secret. Surrounding and repeated slashes are ignored, so `/team//prod/` addresses `team/prod`; IDs always use the normalized form.",
 			},
 			"key": resSchema.StringAttribute{
 				Required: true,
 			},
 			"value": resSchema.StringAttribute{
-				Required:  true,
-				Sensitive: true,
+				Optional:    true,
+				Computed:    true,
+				Sensitive:   true,
+				Description: "The secret value. Required unless `generate` is set, in which case it holds the server-generated value.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"force_delete": resSchema.BoolAttribute{
+				Optional:    true,
+				Description: "Destroy the secret, or delete the old key of a `rename_from` rename, even if it carries the provider's `protected_tag`. Must be applied before the destroy so that it is in state when the delete runs.",
+			},
+			"soft_delete": resSchema.BoolAttribute{
+				Optional:    true,
+				Description: "Soft-delete the secret on destroy, so the server keeps it recoverable for its recovery window, instead of purging it. Requires a server with soft-delete support. Like `force_delete`, it must be applied before the destroy so that it is in state when the delete runs. While it is set, a refresh that finds the secret gone also checks whether it was soft-deleted and says so.",
+			},
+			"generate": resSchema.BoolAttribute{
+				Optional:    true,
+				Description: "Let the server generate the value on create instead of supplying `value`. The generated value is stored in state. Changing this replaces the secret.",
+				PlanModifiers: []planmodifier.Bool{
+					boolplanmodifier.RequiresReplace(),
+				},
+			},
+			"value_sha256": resSchema.StringAttribute{
+				Computed:    true,
+				Description: "Hex SHA-256 of the value stored in Yggdrasil, as written or as last read, so a change made outside Terraform shows up as a diff. Not sensitive; reference it to react to value changes without exposing the value.",
 			},
 			"tags": resSchema.MapAttribute{
 				ElementType: tfTypes.StringType,
 				Optional:    true,
 			},
+			"labels": resSchema.MapAttribute{
+				ElementType: tfTypes.StringType,
+				Optional:    true,
+				Description: "Selector labels. Yggdrasil treats labels as immutable, so changing them replaces the secret.",
+				PlanModifiers: []planmodifier.Map{
+					mapplanmodifier.RequiresReplace(),
+				},
+			},
+			"trim_value": resSchema.BoolAttribute{
+				Optional:    true,
+				Description: "Strip leading and trailing whitespace from `value` before writing it.",
+			},
+			"reject_bom": resSchema.BoolAttribute{
+				Optional:    true,
+				Description: "Fail validation when `value` starts with a UTF-8 byte order mark.",
+			},
+			"inherit_namespace_tags": resSchema.BoolAttribute{
+				Optional:    true,
+				Description: "Merge the namespace's default tags into `tags` when writing, with the resource's own tags taking precedence. The namespace tags are looked up on every create and update; a namespace without tags contributes none. The tags actually written are in `effective_tags`.",
+			},
+			"effective_tags": resSchema.MapAttribute{
+				ElementType: tfTypes.StringType,
+				Computed:    true,
+				Description: "Tags sent with the last write: `tags` merged over the namespace's tags when `inherit_namespace_tags` is set, otherwise the same as `tags`.",
+			},
+			"insecure_skip_verify": resSchema.BoolAttribute{
+				Optional:    true,
+				Description: "Override the provider's `insecure_skip_verify` for this secret only, e.g. for a legacy node with a self-signed certificate. Defaults to the provider setting.",
+			},
+			"manifest": resSchema.SingleNestedAttribute{
+				Computed:    true,
+				Description: "Metadata of the last operation this provider performed on the secret, for compliance reporting: `operation` (`create`, `update`, `rename` or `skipped`), `namespace`, `key` (including `key_prefix`), `version` and `updated_at`. Never contains the value. Null for imported secrets until their first write.",
+				Attributes: map[string]resSchema.Attribute{
+					"operation":  resSchema.StringAttribute{Computed: true},
+					"namespace":  resSchema.StringAttribute{Computed: true},
+					"key":        resSchema.StringAttribute{Computed: true},
+					"version":    resSchema.Int64Attribute{Computed: true},
+					"updated_at": resSchema.StringAttribute{Computed: true},
+				},
+			},
+			"acquire_lock": resSchema.BoolAttribute{
+				Optional:    true,
+				Description: "Take Yggdrasil's advisory lock on the key before each update and release it afterwards, so concurrent applies writing the same key are serialized instead of the last writer silently winning. Waits up to 2 minutes for another holder; the server expires a lock that was never released after 5 minutes. Requires a server with the lock endpoint.",
+			},
+			"ca_cert_path": resSchema.StringAttribute{
+				Optional:    true,
+				Description: "Override the provider's `ca_cert_path` for this secret only. Defaults to the provider setting.",
+			},
+			"change_reason": resSchema.StringAttribute{
+				Optional:    true,
+				Description: "Why the secret is being changed, recorded in Yggdrasil's audit log (sent as `X-Change-Reason`). Overrides the provider's `default_change_reason`. Changing only this attribute does not rewrite the secret.",
+			},
+			"encryption_context": resSchema.MapAttribute{
+				ElementType: tfTypes.StringType,
+				Optional:    true,
+				Description: "Envelope-encryption context (additional authenticated data) sent with every write and read of this secret. Not secret, but must match exactly between write and read. Changing it rewrites the value under the new context.",
+			},
+			"request_headers": resSchema.MapAttribute{
+				ElementType: tfTypes.StringType,
+				Optional:    true,
+				Description: "Extra HTTP headers sent with every API request of this resource, e.g. for a gateway policy engine. They override the provider's default headers such as `Accept`; authentication headers cannot be set.",
+			},
+			"write_request_headers": resSchema.MapAttribute{
+				ElementType: tfTypes.StringType,
+				Optional:    true,
+				Description: "Extra HTTP headers sent only with this resource's mutating requests (anything but GET and HEAD), applied after `request_headers`, e.g. `{ \"X-Intent\" = \"write\" }` alongside `request_headers = { \"X-Intent\" = \"read\" }`. With `protocol = \"graphql\"` every request is a POST and receives them.",
+			},
+			"rename_from": resSchema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "Previous key name. When `key` changes and this matches the key in state, the stored value is moved to the new key and the old key is deleted in one update instead of orphaning it. Changing `value` in the same update rotates the secret while renaming it: the new value is written under the new key before the old key is deleted, and the new key is removed again if that delete fails. Once the rename is applied, remove `rename_from` from the configuration: the next apply clears it from state without writing the secret. Terraform requires the state to match the configuration, so it cannot be cleared while it is still set.",
+			},
+			"trim_trailing_newline": resSchema.BoolAttribute{
+				Optional:    true,
+				Description: "Strip trailing newlines from `value` before writing it, e.g. for values read with `file()`.",
+			},
+			"write_to_file": resSchema.StringAttribute{
+				Optional:    true,
+				Description: "Local path the written value is also saved to (mode 0600) after each successful create or update. The file is removed on destroy. The contents are never logged.",
+			},
+			"value_transform": resSchema.ListAttribute{
+				ElementType: tfTypes.StringType,
+				Optional:    true,
+				Description: "Transforms applied in order to `value` before it is written, after `trim_trailing_newline` and `trim_value`: `base64encode`, `base64decode`, `trim`, `lower` or `upper`. Runs inside the provider, so the value never passes through Terraform functions. `value_sha256` and `write_to_file` reflect the transformed value. Cannot be combined with `generate`.",
+			},
+			"verify_after_write": resSchema.BoolAttribute{
+				Optional:    true,
+				Description: "Read the value back after every write and fail if its length or SHA-256 differs from what was sent, e.g. because a proxy truncated it. Doubles the requests per write.",
+			},
+			"skip_if_namespace_missing": resSchema.BoolAttribute{
+				Optional:    true,
+				Description: "When the namespace does not exist, skip creating the secret with a warning instead of failing. The secret is created by a later apply once the namespace exists.",
+			},
+			"skipped": resSchema.BoolAttribute{
+				Computed:    true,
+				Description: "True while creation is being skipped because of `skip_if_namespace_missing`.",
+			},
 			"version": resSchema.Int64Attribute{
 				Computed: true,
 			},
@@ -67,6 +247,9 @@ func (r *SecretResource) Schema(_ context.Context, _ resource.SchemaRequest, res
 				Computed: true,
 			},
 		},
+		Blocks: map[string]resSchema.Block{
+			"timeouts": timeoutsBlock(),
+		},
 	}
 }
 
@@ -77,31 +260,157 @@ func (r *SecretResource) Configure(_ context.Context, req resource.ConfigureRequ
 	r.client = req.ProviderData.(*APIClient)
 }
 
+func (r *SecretResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var cfg SecretResourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &cfg)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	switch {
+	case cfg.Generate.ValueBool() && !cfg.Value.IsNull():
+		resp.Diagnostics.AddAttributeError(path.Root("value"), "Conflicting attributes",
+			"value cannot be set when generate is true; the server generates it.")
+	case !cfg.Generate.ValueBool() && !cfg.Generate.IsUnknown() && cfg.Value.IsNull():
+		resp.Diagnostics.AddAttributeError(path.Root("value"), "Missing value",
+			"value is required unless generate is true.")
+	}
+
+	if !cfg.ValueTransform.IsNull() {
+		if cfg.Generate.ValueBool() {
+			resp.Diagnostics.AddAttributeError(path.Root("value_transform"), "Conflicting attributes",
+				"value_transform cannot be used with generate; the server generates the stored value.")
+		}
+		for _, t := range valueTransforms(cfg) {
+			if _, ok := transforms[t]; !ok {
+				resp.Diagnostics.AddAttributeError(path.Root("value_transform"), "Invalid value_transform",
+					fmt.Sprintf("%q is not a supported transform (expected one of %s)", t, strings.Join(transformNames(), ", ")))
+			}
+		}
+	}
+
+	checkMapNulls(&resp.Diagnostics, path.Root("tags"), cfg.Tags)
+	checkMapNulls(&resp.Diagnostics, path.Root("labels"), cfg.Labels)
+	checkMapNulls(&resp.Diagnostics, path.Root("encryption_context"), cfg.EncryptionContext)
+	checkMapNulls(&resp.Diagnostics, path.Root("request_headers"), cfg.RequestHeaders)
+	checkMapNulls(&resp.Diagnostics, path.Root("write_request_headers"), cfg.WriteRequestHeaders)
+	checkRequestHeaderNames(&resp.Diagnostics, path.Root("request_headers"), cfg.RequestHeaders)
+	checkRequestHeaderNames(&resp.Diagnostics, path.Root("write_request_headers"), cfg.WriteRequestHeaders)
+	checkTimeouts(&resp.Diagnostics, cfg.Timeouts)
+
+	// Never include the value itself in these diagnostics.
+	if cfg.RejectBOM.ValueBool() && !cfg.Value.IsUnknown() && strings.HasPrefix(cfg.Value.ValueString(), utf8BOM) {
+		resp.Diagnostics.AddAttributeError(path.Root("value"), "Value starts with a byte order mark",
+			"The value begins with a UTF-8 byte order mark (U+FEFF), usually from copying text out of an editor. Remove it or unset reject_bom.")
+	}
+}
+
+// ModifyPlan fills in value_sha256 at plan time so dependents see the new hash
+// in the plan rather than "known after apply".
+func (r *SecretResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+	var plan SecretResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	// rename_from is computed so that it can be cleared from state: Terraform
+	// keeps it while the configuration sets it, and the plan drops it as soon
+	// as the configuration no longer does, without writing the secret.
+	var renameFrom tfTypes.String
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("rename_from"), &renameFrom)...)
+	if renameFrom.IsNull() {
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("rename_from"), tfTypes.StringNull())...)
+	}
+	if resp.Diagnostics.HasError() || !planValueKnown(plan) {
+		return
+	}
+	v, err := writeValue(plan)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("value_transform"), "Value transform failed", err.Error())
+		return
+	}
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("value_sha256"), valueSHA256(v))...)
+}
+
+// planValueKnown reports whether everything writeValue depends on is known.
+func planValueKnown(m SecretResourceModel) bool {
+	return !m.Value.IsUnknown() && !m.TrimTrailingNewline.IsUnknown() && !m.TrimValue.IsUnknown() && !m.ValueTransform.IsUnknown()
+}
+
+// utf8BOM is the UTF-8 encoding of U+FEFF.
+const utf8BOM = "\uFEFF"
+
 func (r *SecretResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
 	var plan SecretResourceModel
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
+	r = r.forModel(plan, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := withOperationTimeout(ctx, &resp.Diagnostics, plan.Timeouts, "create")
+	defer cancel()
+	ctx, reportUnknown := collectUnknownFields(ctx)
+	defer reportUnknown(&resp.Diagnostics)
+	ctx = withRequestHeaders(ctx, &resp.Diagnostics, plan)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if r.skipMissingNamespace(ctx, &resp.Diagnostics, &plan) {
+		if !resp.Diagnostics.HasError() {
+			resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+		}
+		return
+	}
 
+	value, err := writeValue(plan)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("value_transform"), "Value transform failed", err.Error())
+		return
+	}
 	payload := SecretPayload{
 		Namespace: plan.Namespace.ValueString(),
 		Key:       plan.Key.ValueString(),
-		Value:     plan.Value.ValueString(),
-		Tags:      mapFromTF(ctx, plan.Tags),
+		Value:     value,
+		Tags:      mapFromTF(ctx, &resp.Diagnostics, path.Root("tags"), plan.Tags),
+		Labels:    mapFromTF(ctx, &resp.Diagnostics, path.Root("labels"), plan.Labels),
+		Generate:  plan.Generate.ValueBool(),
+	}
+	if resp.Diagnostics.HasError() || !r.inheritTags(ctx, &resp.Diagnostics, plan, &payload) {
+		return
+	}
+	r.noticeRedactedKeys(&resp.Diagnostics, payload)
+	ctx = r.withChangeReason(ctx, &resp.Diagnostics, plan)
+	if resp.Diagnostics.HasError() {
+		return
 	}
 
-	out, err := r.client.UpsertSecret(payload)
+	out, err := r.client.UpsertSecret(ctx, payload)
 	if err != nil {
-		resp.Diagnostics.AddError("Create failed", err.Error())
+		addAPIError(&resp.Diagnostics, "Create failed", err)
 		return
 	}
+	if payload.Generate {
+		payload.Value = out.Value
+		plan.Value = tfTypes.StringValue(out.Value)
+	}
 
 	state := plan
 	state.ID = tfTypes.StringValue(fmt.Sprintf("%s/%s", out.Namespace, out.Key))
 	state.Version = tfTypes.Int64Value(int64(out.Version))
 	state.UpdatedAt = tfTypes.StringValue(out.UpdatedAt)
+	state.ValueSHA256 = tfTypes.StringValue(valueSHA256(payload.Value))
+	state.Manifest = manifestValue("create", out)
+	state.EffectiveTags = tagsValue(payload.Tags)
 	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+	r.audit(&resp.Diagnostics, "create", out.Namespace, out.Key, out.Version)
+	r.verifyWrite(ctx, &resp.Diagnostics, plan, payload)
+	syncLocalFile(&resp.Diagnostics, state, SecretResourceModel{})
 }
 
 func (r *SecretResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
@@ -110,21 +419,121 @@ func (r *SecretResource) Read(ctx context.Context, req resource.ReadRequest, res
 	if resp.Diagnostics.HasError() {
 		return
 	}
+	r = r.forModel(state, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := withOperationTimeout(ctx, &resp.Diagnostics, state.Timeouts, "read")
+	defer cancel()
+	ctx, reportUnknown := collectUnknownFields(ctx)
+	defer reportUnknown(&resp.Diagnostics)
+	ctx = withRequestHeaders(ctx, &resp.Diagnostics, state)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if state.Skipped.ValueBool() {
+		// Once the namespace exists, drop the placeholder so the next plan creates the secret.
+		md, err := r.client.GetNamespaceMetadata(ctx, state.Namespace.ValueString())
+		if err != nil {
+			addAPIError(&resp.Diagnostics, "Read failed", err)
+			return
+		}
+		if md != nil {
+			resp.State.RemoveResource(ctx)
+		}
+		return
+	}
+
+	importVersion, diags := req.Private.GetKey(ctx, importVersionKey)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 
 	ns := state.Namespace.ValueString()
 	key := state.Key.ValueString()
-	out, err := r.client.GetSecret(ns, key)
+	ctx = withEncryptionContext(ctx, mapFromTF(ctx, &resp.Diagnostics, path.Root("encryption_context"), state.EncryptionContext))
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	var out *SecretResponse
+	var err error
+	if len(importVersion) > 0 {
+		// First refresh after "terraform import ns/key@version": read the pinned version once.
+		version, convErr := strconv.Atoi(string(importVersion))
+		if convErr != nil {
+			resp.Diagnostics.AddError("Read failed", fmt.Sprintf("invalid pinned import version %q", importVersion))
+			return
+		}
+		out, err = r.client.GetSecretVersion(ctx, ns, key, version)
+		resp.Diagnostics.Append(resp.Private.SetKey(ctx, importVersionKey, nil)...)
+	} else {
+		out, err = r.client.GetSecret(ctx, ns, key)
+	}
+	if errorStatus(err) == http.StatusForbidden && r.client.assumeReadFromState {
+		// Write-only token: we cannot verify drift, so keep what we last wrote.
+		log.Printf("[WARN] Read of %s/%s forbidden, keeping existing state (assume_read_from_state)", ns, key)
+		resp.Diagnostics.AddWarning("Secret not refreshed",
+			fmt.Sprintf("The token is not allowed to read %s/%s, so the existing state was kept. Drift on this secret cannot be detected.", ns, key))
+		return
+	}
 	if err != nil {
-		resp.Diagnostics.AddError("Read failed", err.Error())
+		addAPIError(&resp.Diagnostics, "Read failed", err)
 		return
 	}
 	if out == nil {
+		if !state.SoftDelete.ValueBool() {
+			resp.State.RemoveResource(ctx)
+			return
+		}
+		// Only to tell the user; a server without soft deletes answers 404 here too.
+		if deleted, err := r.client.GetDeletedSecret(ctx, ns, key); err != nil {
+			log.Printf("[DEBUG] Checking whether %s/%s was soft-deleted failed: %v", ns, key, err)
+		} else if deleted != nil {
+			resp.Diagnostics.AddWarning("Secret was soft-deleted",
+				fmt.Sprintf("%s/%s was soft-deleted at %s and can be recovered until %s. It was removed from state; "+
+					"recover it on the server and import it to manage it again.", ns, key, deleted.DeletedAt, deleted.RecoverableUntil))
+		}
 		resp.State.RemoveResource(ctx)
 		return
 	}
-	state.Version = tfTypes.Int64Value(int64(out.Version))
-	state.UpdatedAt = tfTypes.StringValue(out.UpdatedAt)
+	// Keep what the last write returned when the read does not report them.
+	if out.Version != 0 {
+		state.Version = tfTypes.Int64Value(int64(out.Version))
+	}
+	if out.UpdatedAt != "" {
+		state.UpdatedAt = tfTypes.StringValue(out.UpdatedAt)
+	}
+	// labels forces replacement, so only a real difference may change it: no
+	// labels on the server matches labels left unset.
+	if out.Labels != nil {
+		current := mapFromTF(ctx, &resp.Diagnostics, path.Root("labels"), state.Labels)
+		if !maps.Equal(out.Labels, current) {
+			labels, diags := tfTypes.MapValueFrom(ctx, tfTypes.StringType, out.Labels)
+			resp.Diagnostics.Append(diags...)
+			if len(out.Labels) == 0 {
+				labels = tfTypes.MapNull(tfTypes.StringType)
+			}
+			state.Labels = labels
+		}
+	}
 	// Jangan set ulang Value dari remote bila API tidak mengembalikan (atau redaksi)
+	// kecuali state belum punya value sama sekali (mis. setelah import).
+	if out.Value != "" {
+		// Hash the stored, already transformed value on every read, so that a
+		// change made outside Terraform shows up against the planned hash of
+		// the configured value.
+		state.ValueSHA256 = tfTypes.StringValue(valueSHA256(out.Value))
+		if state.Value.IsNull() {
+			state.Value = tfTypes.StringValue(out.Value)
+		}
+	}
+	if state.ValueSHA256.IsNull() && !state.Value.IsNull() {
+		if v, err := writeValue(state); err == nil {
+			state.ValueSHA256 = tfTypes.StringValue(valueSHA256(v))
+		}
+	}
 	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
 }
 
@@ -136,23 +545,119 @@ func (r *SecretResource) Update(ctx context.Context, req resource.UpdateRequest,
 	if resp.Diagnostics.HasError() {
 		return
 	}
+	r = r.forModel(plan, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := withOperationTimeout(ctx, &resp.Diagnostics, plan.Timeouts, "update")
+	defer cancel()
+	ctx, reportUnknown := collectUnknownFields(ctx)
+	defer reportUnknown(&resp.Diagnostics)
+	ctx = withRequestHeaders(ctx, &resp.Diagnostics, plan)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if state.Skipped.ValueBool() {
+		if r.skipMissingNamespace(ctx, &resp.Diagnostics, &plan) {
+			if !resp.Diagnostics.HasError() {
+				resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+			}
+			return
+		}
+		// The namespace exists now; fall through and write the secret for the first time.
+	} else if !secretChanged(plan, state) {
+		// Only provider-side settings changed; avoid a write and a needless version bump.
+		plan.ID = state.ID
+		plan.Version = state.Version
+		plan.UpdatedAt = state.UpdatedAt
+		// secretChanged reported no change, so the transform succeeded.
+		v, _ := writeValue(plan)
+		plan.ValueSHA256 = tfTypes.StringValue(valueSHA256(v))
+		plan.Skipped = tfTypes.BoolValue(false)
+		plan.Manifest = state.Manifest
+		plan.EffectiveTags = state.EffectiveTags
+		resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+		syncLocalFile(&resp.Diagnostics, plan, state)
+		return
+	}
+	plan.Skipped = tfTypes.BoolValue(false)
 
+	value, err := writeValue(plan)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("value_transform"), "Value transform failed", err.Error())
+		return
+	}
 	payload := SecretPayload{
 		Namespace: plan.Namespace.ValueString(),
 		Key:       plan.Key.ValueString(),
-		Value:     plan.Value.ValueString(),
-		Tags:      mapFromTF(ctx, plan.Tags),
+		Value:     value,
+		Tags:      mapFromTF(ctx, &resp.Diagnostics, path.Root("tags"), plan.Tags),
+		Labels:    mapFromTF(ctx, &resp.Diagnostics, path.Root("labels"), plan.Labels),
+	}
+	if resp.Diagnostics.HasError() || !r.inheritTags(ctx, &resp.Diagnostics, plan, &payload) {
+		return
 	}
-	out, err := r.client.UpsertSecret(payload)
+	r.noticeRedactedKeys(&resp.Diagnostics, payload)
+	ctx = r.withChangeReason(ctx, &resp.Diagnostics, plan)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if plan.AcquireLock.ValueBool() {
+		release, err := r.client.AcquireLock(ctx, payload.Namespace, payload.Key)
+		if errors.Is(err, errSecretLocked) {
+			resp.Diagnostics.AddError("Secret is locked by another operation",
+				err.Error()+"\n\nAnother apply is writing this secret. Retry once it has finished.")
+			return
+		}
+		if err != nil {
+			addAPIError(&resp.Diagnostics, "Acquiring lock failed", err)
+			return
+		}
+		defer release()
+	}
+
+	prior := state
+	if isRename(plan, state) {
+		if plan.ForceDelete.ValueBool() {
+			ctx = withForceDelete(ctx)
+		}
+		out, err := r.rename(ctx, payload, state)
+		if err != nil {
+			addAPIError(&resp.Diagnostics, "Rename failed", err)
+			return
+		}
+		state = plan
+		state.ID = tfTypes.StringValue(fmt.Sprintf("%s/%s", out.Namespace, out.Key))
+		state.Version = tfTypes.Int64Value(int64(out.Version))
+		state.UpdatedAt = tfTypes.StringValue(out.UpdatedAt)
+		state.ValueSHA256 = tfTypes.StringValue(valueSHA256(payload.Value))
+		state.Manifest = manifestValue("rename", out)
+		state.EffectiveTags = tagsValue(payload.Tags)
+		resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+		r.audit(&resp.Diagnostics, "rename", out.Namespace, out.Key, out.Version)
+		r.verifyWrite(ctx, &resp.Diagnostics, plan, payload)
+		syncLocalFile(&resp.Diagnostics, plan, prior)
+		return
+	}
+
+	out, err := r.client.UpsertSecret(ctx, payload)
 	if err != nil {
-		resp.Diagnostics.AddError("Update failed", err.Error())
+		addAPIError(&resp.Diagnostics, "Update failed", err)
 		return
 	}
 	state = plan
 	state.ID = tfTypes.StringValue(fmt.Sprintf("%s/%s", out.Namespace, out.Key))
 	state.Version = tfTypes.Int64Value(int64(out.Version))
 	state.UpdatedAt = tfTypes.StringValue(out.UpdatedAt)
+	state.ValueSHA256 = tfTypes.StringValue(valueSHA256(payload.Value))
+	state.Manifest = manifestValue("update", out)
+	state.EffectiveTags = tagsValue(payload.Tags)
 	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+	r.audit(&resp.Diagnostics, "update", out.Namespace, out.Key, out.Version)
+	r.verifyWrite(ctx, &resp.Diagnostics, plan, payload)
+	syncLocalFile(&resp.Diagnostics, plan, prior)
 }
 
 func (r *SecretResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
@@ -161,32 +666,520 @@ func (r *SecretResource) Delete(ctx context.Context, req resource.DeleteRequest,
 	if resp.Diagnostics.HasError() {
 		return
 	}
-	if err := r.client.DeleteSecret(state.Namespace.ValueString(), state.Key.ValueString()); err != nil {
-		resp.Diagnostics.AddError("Delete failed", err.Error())
+	if state.Skipped.ValueBool() {
+		// Nothing was ever written.
+		return
+	}
+	r = r.forModel(state, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := withOperationTimeout(ctx, &resp.Diagnostics, state.Timeouts, "delete")
+	defer cancel()
+	ctx, reportUnknown := collectUnknownFields(ctx)
+	defer reportUnknown(&resp.Diagnostics)
+	ctx = withRequestHeaders(ctx, &resp.Diagnostics, state)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	// The client checks the tags on the server before deleting; the tags in
+	// state are checked here as well in case they were removed outside Terraform.
+	if state.ForceDelete.ValueBool() {
+		ctx = withForceDelete(ctx)
+	} else if tag := r.client.protectedBy(mapFromTF(ctx, &resp.Diagnostics, path.Root("tags"), state.Tags)); tag != "" {
+		addAPIError(&resp.Diagnostics, "Delete failed", &protectedError{
+			Namespace: normalizeNamespace(state.Namespace.ValueString()), Key: state.Key.ValueString(), Tag: tag})
+		return
+	}
+	ctx = r.withChangeReason(ctx, &resp.Diagnostics, state)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	op, del := "delete", r.client.DeleteSecret
+	if state.SoftDelete.ValueBool() {
+		op, del = "soft_delete", r.client.SoftDeleteSecret
+	}
+	if err := del(ctx, state.Namespace.ValueString(), state.Key.ValueString()); err != nil {
+		addAPIError(&resp.Diagnostics, "Delete failed", err)
+		return
+	}
+	r.audit(&resp.Diagnostics, op, state.Namespace.ValueString(), state.Key.ValueString(), int(state.Version.ValueInt64()))
+	if p := state.WriteToFile.ValueString(); p != "" {
+		removeLocalFile(&resp.Diagnostics, p)
 	}
 }
 
-func (r *SecretResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
-	// import_id format: "namespace/key"
-	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), req.ID)...)
-	// Split manual
-	var ns, key string
-	for i, ch := range req.ID {
-		if ch == '/' {
-			ns = req.ID[:i]
-			key = req.ID[i+1:]
-			break
+// forModel returns r, or a copy of r whose client applies the TLS overrides
+// of m. Turning verification off is always called out with a warning.
+func (r *SecretResource) forModel(m SecretResourceModel, diags *diag.Diagnostics) *SecretResource {
+	if m.InsecureSkipVerify.IsNull() && m.CACertPath.IsNull() {
+		return r
+	}
+	insecure := r.client.cfg.InsecureSkipVerify
+	if !m.InsecureSkipVerify.IsNull() {
+		insecure = m.InsecureSkipVerify.ValueBool()
+	}
+	caCertPath := r.client.cfg.CACertPath
+	if !m.CACertPath.IsNull() {
+		caCertPath = m.CACertPath.ValueString()
+	}
+	if m.InsecureSkipVerify.ValueBool() {
+		diags.AddAttributeWarning(path.Root("insecure_skip_verify"), "TLS verification disabled",
+			fmt.Sprintf("TLS certificate verification is off for %s/%s. Connections for this secret can be intercepted.",
+				m.Namespace.ValueString(), m.Key.ValueString()))
+	}
+	client, err := r.client.withTLS(insecure, caCertPath)
+	if err != nil {
+		diags.AddError("Failed to create API client", fmt.Sprintf("Applying the resource's TLS settings: %s", err))
+		return r
+	}
+	return &SecretResource{client: client}
+}
+
+// skipMissingNamespace reports whether writing m must be skipped because
+// skip_if_namespace_missing is set and the namespace does not exist. m is then
+// turned into the placeholder state of a skipped secret. A failed check is
+// reported in diags and also returns true.
+func (r *SecretResource) skipMissingNamespace(ctx context.Context, diags *diag.Diagnostics, m *SecretResourceModel) bool {
+	m.Skipped = tfTypes.BoolValue(false)
+	if !m.SkipIfNamespaceMissing.ValueBool() {
+		return false
+	}
+	ns := m.Namespace.ValueString()
+	md, err := r.client.GetNamespaceMetadata(ctx, ns)
+	if err != nil {
+		addAPIError(diags, "Namespace check failed", err)
+		return true
+	}
+	if md != nil {
+		return false
+	}
+
+	log.Printf("[INFO] Namespace %s does not exist, skipping %s/%s (skip_if_namespace_missing)", ns, ns, m.Key.ValueString())
+	diags.AddWarning("Secret skipped",
+		fmt.Sprintf("Namespace %q does not exist, so %s/%s was not created (skip_if_namespace_missing). It will be created by a later apply once the namespace exists.",
+			ns, ns, m.Key.ValueString()))
+	m.ID = tfTypes.StringValue(fmt.Sprintf("%s/%s", normalizeNamespace(ns), r.client.fullKey(m.Key.ValueString())))
+	m.Version = tfTypes.Int64Null()
+	m.UpdatedAt = tfTypes.StringNull()
+	if m.Value.IsUnknown() {
+		m.Value = tfTypes.StringNull()
+	}
+	m.ValueSHA256 = tfTypes.StringNull()
+	if v, err := writeValue(*m); err == nil && !m.Value.IsNull() && planValueKnown(*m) {
+		m.ValueSHA256 = tfTypes.StringValue(valueSHA256(v))
+	}
+	m.Skipped = tfTypes.BoolValue(true)
+	m.EffectiveTags = tfTypes.MapNull(tfTypes.StringType)
+	m.Manifest = manifestValue("skipped", &SecretResponse{Namespace: normalizeNamespace(ns), Key: r.client.fullKey(m.Key.ValueString())})
+	return true
+}
+
+// isRename reports whether an update should move the secret from the key in
+// state to the planned key, as requested via rename_from.
+func isRename(plan, state SecretResourceModel) bool {
+	return !plan.RenameFrom.IsNull() &&
+		plan.RenameFrom.Equal(state.Key) &&
+		!plan.Key.Equal(state.Key)
+}
+
+// rename writes p under p.Key and deletes the old key. When the value is
+// unchanged, the value actually stored under the old key is moved; otherwise
+// p.Value is written, rotating the secret in the same update. If the old key
+// cannot be deleted, the new key is removed again so the secret is never left
+// under both names. The old key stays readable until the new one is written.
+func (r *SecretResource) rename(ctx context.Context, p SecretPayload, state SecretResourceModel) (*SecretResponse, error) {
+	oldNs := state.Namespace.ValueString()
+	oldKey := state.Key.ValueString()
+	if p.Namespace != oldNs {
+		return nil, fmt.Errorf("rename_from only supports renames within a namespace (%q -> %q)", oldNs, p.Namespace)
+	}
+	if v, err := writeValue(state); err == nil && p.Value == v {
+		old, err := r.client.GetSecret(ctx, oldNs, oldKey)
+		if err != nil {
+			return nil, fmt.Errorf("reading %s/%s: %w", oldNs, oldKey, err)
+		}
+		if old == nil {
+			return nil, fmt.Errorf("source key %s/%s does not exist", oldNs, oldKey)
+		}
+		p.Value = old.Value
+	}
+
+	out, err := r.client.UpsertSecret(ctx, p)
+	if err != nil {
+		return nil, fmt.Errorf("writing %s/%s: %w", p.Namespace, p.Key, err)
+	}
+
+	if err := r.client.DeleteSecret(ctx, oldNs, oldKey); err != nil {
+		// The new key may carry protected_tag too; it was only just written.
+		if rbErr := r.client.DeleteSecret(withForceDelete(ctx), p.Namespace, p.Key); rbErr != nil {
+			return nil, fmt.Errorf("deleting %s/%s: %w (rollback of %s/%s also failed: %v)", oldNs, oldKey, err, p.Namespace, p.Key, rbErr)
+		}
+		return nil, fmt.Errorf("deleting %s/%s: %w (new key %s rolled back)", oldNs, oldKey, err, p.Key)
+	}
+	return out, nil
+}
+
+// inheritTags merges the namespace's tags under p.Tags when m sets
+// inherit_namespace_tags. It reports false after adding an error to diags.
+func (r *SecretResource) inheritTags(ctx context.Context, diags *diag.Diagnostics, m SecretResourceModel, p *SecretPayload) bool {
+	if !m.InheritNamespaceTags.ValueBool() {
+		return true
+	}
+	nsTags, err := r.client.GetNamespaceTags(ctx, p.Namespace)
+	if err != nil {
+		addAPIError(diags, "Reading namespace tags failed", err)
+		return false
+	}
+	if len(nsTags) == 0 {
+		return true
+	}
+	merged := make(map[string]string, len(nsTags)+len(p.Tags))
+	for k, v := range nsTags {
+		merged[k] = v
+	}
+	for k, v := range p.Tags {
+		merged[k] = v
+	}
+	p.Tags = merged
+	return true
+}
+
+// tagsValue converts tags to a map attribute; nil becomes an empty map.
+func tagsValue(tags map[string]string) tfTypes.Map {
+	elems := make(map[string]attr.Value, len(tags))
+	for k, v := range tags {
+		elems[k] = tfTypes.StringValue(v)
+	}
+	return tfTypes.MapValueMust(tfTypes.StringType, elems)
+}
+
+// noticeRedactedKeys tells the user, once per key and run, that a tag or label
+// key matches the log redaction rules and will show up masked in DEBUG logs.
+func (r *SecretResource) noticeRedactedKeys(diags *diag.Diagnostics, p SecretPayload) {
+	var keys []string
+	for _, m := range []map[string]string{p.Tags, p.Labels} {
+		for k := range m {
+			if utils.IsSensitiveKey(k) {
+				keys = append(keys, k)
+			}
 		}
 	}
+	keys = r.client.unnoticedKeys(keys)
+	if len(keys) == 0 {
+		return
+	}
+	sort.Strings(keys)
+	diags.AddWarning("Tag values masked in logs",
+		fmt.Sprintf("The tag/label keys %s match the provider's log redaction rules, so their values are shown as %q in DEBUG logs. "+
+			"This only affects logging; the values stored in Yggdrasil are unchanged.", strings.Join(keys, ", "), utils.RedactionMask))
+}
+
+// withRequestHeaders attaches m's request_headers and write_request_headers
+// to ctx.
+func withRequestHeaders(ctx context.Context, diags *diag.Diagnostics, m SecretResourceModel) context.Context {
+	ctx = withHeaders(ctx, mapFromTF(ctx, diags, path.Root("request_headers"), m.RequestHeaders))
+	return withWriteHeaders(ctx, mapFromTF(ctx, diags, path.Root("write_request_headers"), m.WriteRequestHeaders))
+}
+
+// withChangeReason attaches the effective change reason and the encryption
+// context of m to ctx so the API requests of this operation carry them.
+func (r *SecretResource) withChangeReason(ctx context.Context, diags *diag.Diagnostics, m SecretResourceModel) context.Context {
+	reason := m.ChangeReason.ValueString()
+	if reason == "" {
+		reason = r.client.defaultChangeReason
+	}
+	ctx = withEncryptionContext(ctx, mapFromTF(ctx, diags, path.Root("encryption_context"), m.EncryptionContext))
+	return withHeaders(ctx, map[string]string{"X-Change-Reason": reason})
+}
+
+// verifyWrite reads p back when verify_after_write is set and reports an error
+// if the stored value differs from the one sent. Only lengths and hashes are
+// reported, never the value.
+func (r *SecretResource) verifyWrite(ctx context.Context, diags *diag.Diagnostics, m SecretResourceModel, p SecretPayload) {
+	if !m.VerifyAfterWrite.ValueBool() {
+		return
+	}
+	// A replica may not have caught up with the write yet.
+	got, err := r.client.getSecret(ctx, p.Namespace, p.Key, readOptions{primary: true})
+	if err != nil {
+		addAPIError(diags, "Verification read failed", err)
+		return
+	}
+	if got == nil {
+		diags.AddError("Write verification failed",
+			fmt.Sprintf("%s/%s was written but could not be read back.", p.Namespace, p.Key))
+		return
+	}
+	if got.Value != p.Value {
+		diags.AddError("Write verification failed",
+			fmt.Sprintf("The value stored at %s/%s does not match what was sent: sent %d bytes (sha256 %s), stored %d bytes (sha256 %s). "+
+				"Something between the provider and Yggdrasil may be truncating or rewriting values.",
+				p.Namespace, p.Key, len(p.Value), valueSHA256(p.Value), len(got.Value), valueSHA256(got.Value)))
+	}
+}
+
+// audit records a successful mutation; failures only warn so they never fail the apply.
+var manifestAttrTypes = map[string]attr.Type{
+	"operation":  tfTypes.StringType,
+	"namespace":  tfTypes.StringType,
+	"key":        tfTypes.StringType,
+	"version":    tfTypes.Int64Type,
+	"updated_at": tfTypes.StringType,
+}
+
+// manifestValue builds the manifest attribute for an operation on out. A zero
+// version or empty timestamp (nothing was written) is recorded as null.
+func manifestValue(op string, out *SecretResponse) tfTypes.Object {
+	version, updatedAt := versionValues(out)
+	return tfTypes.ObjectValueMust(manifestAttrTypes, map[string]attr.Value{
+		"operation":  tfTypes.StringValue(op),
+		"namespace":  tfTypes.StringValue(out.Namespace),
+		"key":        tfTypes.StringValue(out.Key),
+		"version":    version,
+		"updated_at": updatedAt,
+	})
+}
+
+func (r *SecretResource) audit(diags *diag.Diagnostics, op, ns, key string, version int) {
+	if err := r.client.Audit(op, ns, key, version); err != nil {
+		diags.AddWarning("Audit log write failed", err.Error())
+	}
+}
+
+func (r *SecretResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	// import_id format: "namespace/key" or "namespace/key@version"
+	id, versionStr, pinned := strings.Cut(req.ID, "@")
+	ns, key, ok := splitImportID(id)
+	if !ok {
+		resp.Diagnostics.AddError("Invalid import ID",
+			fmt.Sprintf("Expected \"namespace/key\" or \"namespace/key@version\", got %q", req.ID))
+		return
+	}
+
+	// IDs carry the full prefixed key; the key attribute is what users write in config.
+	id = fmt.Sprintf("%s/%s", ns, key)
+	if r.client != nil {
+		key = strings.TrimPrefix(key, r.client.keyPrefix)
+		id = fmt.Sprintf("%s/%s", ns, r.client.fullKey(key))
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), id)...)
 	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("namespace"), ns)...)
 	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("key"), key)...)
+
+	if pinned {
+		version, err := strconv.Atoi(versionStr)
+		if err != nil || version < 1 {
+			resp.Diagnostics.AddError("Invalid import ID",
+				fmt.Sprintf("Version in %q must be a positive integer, got %q", req.ID, versionStr))
+			return
+		}
+		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("version"), int64(version))...)
+		resp.Diagnostics.Append(resp.Private.SetKey(ctx, importVersionKey, []byte(strconv.Itoa(version)))...)
+	}
+}
+
+// splitImportID splits a "namespace/key" import ID at its last slash, as
+// namespaces may be hierarchical but keys cannot contain "/". The namespace is
+// returned normalized.
+func splitImportID(id string) (ns, key string, ok bool) {
+	i := strings.LastIndex(id, "/")
+	if i < 0 {
+		return "", "", false
+	}
+	ns, key = normalizeNamespace(id[:i]), id[i+1:]
+	return ns, key, ns != "" && key != ""
+}
+
+// writeValue returns the value sent to Yggdrasil after applying the
+// normalizations and value_transform steps the user opted into. Errors never
+// include the value.
+func writeValue(m SecretResourceModel) (string, error) {
+	v := m.Value.ValueString()
+	if m.TrimTrailingNewline.ValueBool() {
+		v = strings.TrimRight(v, "\r\n")
+	}
+	if m.TrimValue.ValueBool() {
+		v = strings.TrimSpace(v)
+	}
+	for i, name := range valueTransforms(m) {
+		fn, ok := transforms[name]
+		if !ok {
+			return "", fmt.Errorf("unsupported transform %q", name)
+		}
+		var err error
+		if v, err = fn(v); err != nil {
+			return "", fmt.Errorf("value_transform[%d] (%s): %w", i, name, err)
+		}
+	}
+	return v, nil
+}
+
+// transforms are the steps value_transform accepts.
+var transforms = map[string]func(string) (string, error){
+	"base64encode": func(v string) (string, error) { return base64.StdEncoding.EncodeToString([]byte(v)), nil },
+	"base64decode": func(v string) (string, error) {
+		b, err := base64.StdEncoding.DecodeString(v)
+		if err != nil {
+			// The error from encoding/base64 only carries an offset, never the input.
+			return "", fmt.Errorf("value is not valid base64: %w", err)
+		}
+		return string(b), nil
+	},
+	"trim":  func(v string) (string, error) { return strings.TrimSpace(v), nil },
+	"lower": func(v string) (string, error) { return strings.ToLower(v), nil },
+	"upper": func(v string) (string, error) { return strings.ToUpper(v), nil },
+}
+
+func transformNames() []string {
+	names := make([]string, 0, len(transforms))
+	for name := range transforms {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// valueTransforms returns the configured value_transform steps; unknown
+// elements are skipped (callers check planValueKnown first).
+func valueTransforms(m SecretResourceModel) []string {
+	var out []string
+	for _, e := range m.ValueTransform.Elements() {
+		if s, ok := e.(tfTypes.String); ok && !s.IsUnknown() {
+			out = append(out, s.ValueString())
+		}
+	}
+	return out
+}
+
+// secretChanged reports whether any attribute that is actually sent to
+// Yggdrasil differs between plan and state.
+func secretChanged(plan, state SecretResourceModel) bool {
+	return normalizeNamespace(plan.Namespace.ValueString()) != normalizeNamespace(state.Namespace.ValueString()) ||
+		!plan.Key.Equal(state.Key) ||
+		valueChanged(plan, state) ||
+		!plan.Tags.Equal(state.Tags) ||
+		!plan.Labels.Equal(state.Labels) ||
+		!plan.InheritNamespaceTags.Equal(state.InheritNamespaceTags) ||
+		!plan.EncryptionContext.Equal(state.EncryptionContext)
+}
+
+// valueChanged reports whether plan writes a different value than the one
+// stored. It compares against value_sha256 in state, the hash of the stored
+// value as last read or written: state's value may itself be the stored,
+// already transformed value (after import), which must not be transformed
+// again. A transform error counts as a change so that the write reports it.
+func valueChanged(plan, state SecretResourceModel) bool {
+	pv, err := writeValue(plan)
+	if err != nil {
+		return true
+	}
+	if !state.ValueSHA256.IsNull() && !state.ValueSHA256.IsUnknown() {
+		return valueSHA256(pv) != state.ValueSHA256.ValueString()
+	}
+	sv, err := writeValue(state)
+	return err != nil || pv != sv
+}
+
+// syncLocalFile saves the value of plan to its write_to_file path, removing
+// the file at prior's path if the path changed.
+func syncLocalFile(diags *diag.Diagnostics, plan, prior SecretResourceModel) {
+	newPath := plan.WriteToFile.ValueString()
+	if oldPath := prior.WriteToFile.ValueString(); oldPath != "" && oldPath != newPath {
+		removeLocalFile(diags, oldPath)
+	}
+	if newPath == "" {
+		return
+	}
+	v, err := writeValue(plan)
+	if err == nil {
+		err = writeLocalFile(newPath, v)
+	}
+	if err != nil {
+		diags.AddAttributeError(path.Root("write_to_file"), "Writing local file failed", err.Error())
+		return
+	}
+	log.Printf("[DEBUG] Wrote secret value to %s", newPath)
+}
+
+// writeLocalFile atomically replaces name with value, readable only by the
+// owner. Errors mention the path only, never the contents.
+func writeLocalFile(name, value string) error {
+	name = filepath.Clean(name)
+	f, err := os.CreateTemp(filepath.Dir(name), "."+filepath.Base(name)+".tmp*") // created 0600
+	if err != nil {
+		return fmt.Errorf("creating temporary file for %s: %w", name, err)
+	}
+	tmp := f.Name()
+	_, err = f.WriteString(value)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp, name)
+	}
+	if err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("writing %s: %w", name, err)
+	}
+	return nil
 }
-func mapFromTF(ctx context.Context, m tfTypes.Map) map[string]string {
-	if m.IsNull() || m.IsUnknown() {
+
+// removeLocalFile deletes a file written for write_to_file; a missing file is fine.
+func removeLocalFile(diags *diag.Diagnostics, name string) {
+	if err := os.Remove(filepath.Clean(name)); err != nil && !os.IsNotExist(err) {
+		diags.AddWarning("Removing local file failed", err.Error())
+	}
+}
+
+// valueSHA256 returns the hex-encoded SHA-256 of v.
+func valueSHA256(v string) string {
+	sum := sha256.Sum256([]byte(v))
+	return hex.EncodeToString(sum[:])
+}
+
+// mapFromTF converts the string map at p, which is nil when null. Unknown and
+// null elements are reported on diags instead of being dropped, so a tag that
+// is not known yet never silently goes missing from a write.
+func mapFromTF(ctx context.Context, diags *diag.Diagnostics, p path.Path, m tfTypes.Map) map[string]string {
+	if m.IsNull() {
 		return nil
 	}
-	out := make(map[string]string)
-	// false = jangan set unknown ke null; sesuaikan kebutuhan
-	_ = m.ElementsAs(ctx, &out, false)
+	if m.IsUnknown() {
+		diags.AddAttributeError(p, "Value not known", "The map is not known yet. It must be known before the provider can use it.")
+		return nil
+	}
+	out := make(map[string]string, len(m.Elements()))
+	for k, v := range m.Elements() {
+		s, ok := v.(tfTypes.String)
+		switch {
+		case !ok:
+			diags.AddAttributeError(p.AtMapKey(k), "Invalid map value", fmt.Sprintf("Expected a string for %q, got %s.", k, v.Type(ctx)))
+		case s.IsUnknown():
+			diags.AddAttributeError(p.AtMapKey(k), "Value not known",
+				fmt.Sprintf("The value for %q is not known yet, e.g. because it is computed by a resource that has not been applied. "+
+					"It must be known before the provider can use it; it was not dropped.", k))
+		case s.IsNull():
+			diags.AddAttributeError(p.AtMapKey(k), "Null map value", nullMapValueDetail(k))
+		default:
+			out[k] = s.ValueString()
+		}
+	}
 	return out
 }
+
+// checkMapNulls reports null elements of m at validation time. Terraform
+// allows them in map literals, but Yggdrasil has no null tags or values.
+// Unknown elements are left for mapFromTF, once they are known.
+func checkMapNulls(diags *diag.Diagnostics, p path.Path, m tfTypes.Map) {
+	for k, v := range m.Elements() {
+		if v.IsNull() {
+			diags.AddAttributeError(p.AtMapKey(k), "Null map value", nullMapValueDetail(k))
+		}
+	}
+}
+
+func nullMapValueDetail(k string) string {
+	return fmt.Sprintf("The value for %q is null. Use an empty string, or remove the key.", k)
+}
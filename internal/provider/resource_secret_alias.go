@@ -0,0 +1,179 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	resSchema "github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	stringplanmodifier "github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	tfTypes "github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var _ resource.Resource = &SecretAliasResource{}
+var _ resource.ResourceWithImportState = &SecretAliasResource{}
+
+func NewSecretAliasResource() resource.Resource {
+	return &SecretAliasResource{}
+}
+
+type SecretAliasResource struct {
+	client *APIClient
+}
+
+type SecretAliasResourceModel struct {
+	ID              tfTypes.String `tfsdk:"id"`
+	Namespace       tfTypes.String `tfsdk:"namespace"`
+	Key             tfTypes.String `tfsdk:"key"`
+	TargetNamespace tfTypes.String `tfsdk:"target_namespace"`
+	TargetKey       tfTypes.String `tfsdk:"target_key"`
+	RequireTarget   tfTypes.Bool   `tfsdk:"require_target"`
+}
+
+func (r *SecretAliasResource) Metadata(_ context.Context, _ resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = "yggdrasil_secret_alias"
+}
+
+func (r *SecretAliasResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = resSchema.Schema{
+		Attributes: map[string]resSchema.Attribute{
+			"id": resSchema.StringAttribute{
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"namespace": resSchema.StringAttribute{
+				Required: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"key": resSchema.StringAttribute{
+				Required: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"target_namespace": resSchema.StringAttribute{
+				Required:    true,
+				Description: "Namespace of the secret the alias points at.",
+			},
+			"target_key": resSchema.StringAttribute{
+				Required:    true,
+				Description: "Key of the secret the alias points at.",
+			},
+			"require_target": resSchema.BoolAttribute{
+				Optional:    true,
+				Description: "Fail when the target secret does not exist. Defaults to true.",
+			},
+		},
+	}
+}
+
+func (r *SecretAliasResource) Configure(_ context.Context, req resource.ConfigureRequest, _ *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	r.client = req.ProviderData.(*APIClient)
+}
+
+func (r *SecretAliasResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan SecretAliasResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, reportUnknown := collectUnknownFields(ctx)
+	defer reportUnknown(&resp.Diagnostics)
+	if err := r.put(ctx, plan); err != nil {
+		addAPIError(&resp.Diagnostics, "Create failed", err)
+		return
+	}
+	plan.ID = tfTypes.StringValue(fmt.Sprintf("%s/%s", normalizeNamespace(plan.Namespace.ValueString()), plan.Key.ValueString()))
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *SecretAliasResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state SecretAliasResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, reportUnknown := collectUnknownFields(ctx)
+	defer reportUnknown(&resp.Diagnostics)
+
+	out, err := r.client.GetAlias(ctx, state.Namespace.ValueString(), state.Key.ValueString())
+	if err != nil {
+		addAPIError(&resp.Diagnostics, "Read failed", err)
+		return
+	}
+	if out == nil {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	state.TargetNamespace = tfTypes.StringValue(out.TargetNamespace)
+	state.TargetKey = tfTypes.StringValue(out.TargetKey)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+func (r *SecretAliasResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan SecretAliasResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, reportUnknown := collectUnknownFields(ctx)
+	defer reportUnknown(&resp.Diagnostics)
+	if err := r.put(ctx, plan); err != nil {
+		addAPIError(&resp.Diagnostics, "Update failed", err)
+		return
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *SecretAliasResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state SecretAliasResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if err := r.client.DeleteAlias(ctx, state.Namespace.ValueString(), state.Key.ValueString()); err != nil {
+		addAPIError(&resp.Diagnostics, "Delete failed", err)
+	}
+}
+
+func (r *SecretAliasResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	// import_id format: "namespace/key"
+	ns, key, ok := splitImportID(req.ID)
+	if !ok {
+		resp.Diagnostics.AddError("Invalid import ID", fmt.Sprintf("Expected \"namespace/key\", got %q", req.ID))
+		return
+	}
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), ns+"/"+key)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("namespace"), ns)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("key"), key)...)
+}
+
+// put checks the target (unless require_target is false) and writes the alias.
+func (r *SecretAliasResource) put(ctx context.Context, m SecretAliasResourceModel) error {
+	tNs := m.TargetNamespace.ValueString()
+	tKey := m.TargetKey.ValueString()
+	if m.RequireTarget.IsNull() || m.RequireTarget.ValueBool() {
+		target, err := r.client.GetSecret(ctx, tNs, tKey)
+		if err != nil {
+			return fmt.Errorf("checking target %s/%s: %w", tNs, tKey, err)
+		}
+		if target == nil {
+			return fmt.Errorf("target secret %s/%s does not exist (set require_target = false to allow dangling aliases)", tNs, tKey)
+		}
+	}
+	return r.client.PutAlias(ctx, SecretAlias{
+		Namespace:       m.Namespace.ValueString(),
+		Key:             m.Key.ValueString(),
+		TargetNamespace: tNs,
+		TargetKey:       tKey,
+	})
+}
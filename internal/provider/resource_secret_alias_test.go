@@ -0,0 +1,23 @@
+package provider
+
+import "testing"
+
+func TestSecretAliasResourceImportOfHierarchicalNamespaces(t *testing.T) {
+	s := resourceSchema(t, &SecretAliasResource{})
+	for _, id := range []string{"team/prod/alias", "/team//prod/alias"} {
+		state, _, errs := testImport(t, s, "yggdrasil_secret_alias", id)
+		if errs != nil {
+			t.Errorf("import %q: %v", id, errs)
+			continue
+		}
+		if got := attrString(t, state, "namespace"); got != "team/prod" {
+			t.Errorf("import %q: namespace = %q, want %q", id, got, "team/prod")
+		}
+		if got := attrString(t, state, "key"); got != "alias" {
+			t.Errorf("import %q: key = %q, want %q", id, got, "alias")
+		}
+		if got := attrString(t, state, "id"); got != "team/prod/alias" {
+			t.Errorf("import %q: id = %q, want %q", id, got, "team/prod/alias")
+		}
+	}
+}
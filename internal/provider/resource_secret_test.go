@@ -0,0 +1,416 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	resSchema "github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+func TestSecretResourceUnchangedApplyDoesNotWrite(t *testing.T) {
+	srv := newFakeServer(t)
+	r := &SecretResource{client: newTestClient(t, srv.URL, Config{})}
+	s := resourceSchema(t, r)
+
+	config := tfObject(t, s, map[string]tftypes.Value{
+		"namespace": tfString("team"),
+		"key":       tfString("db_password"),
+		"value":     tfString("s3cret"),
+		"tags":      tfStringMap(map[string]string{"owner": "platform"}),
+	})
+	state := testCreate(t, r, s, config)
+	if n := srv.count("PUT", ""); n != 1 {
+		t.Fatalf("create sent %d PUTs, want 1", n)
+	}
+
+	// Second apply with the same config: refresh, then an update in which
+	// only a provider-side attribute differs.
+	state = testRead(t, r, s, state)
+	plan := withAttrs(t, state, map[string]tftypes.Value{"change_reason": tfString("quarterly review")})
+	state = testUpdate(t, r, s, plan, state)
+	if n := srv.count("PUT", ""); n != 1 {
+		t.Fatalf("unchanged apply sent %d PUTs in total, want 1", n)
+	}
+	if got := attrString(t, state, "change_reason"); got != "quarterly review" {
+		t.Errorf("change_reason in state = %q, want it carried forward", got)
+	}
+
+	// A real change still writes.
+	plan = withAttrs(t, state, map[string]tftypes.Value{"value": tfString("rotated")})
+	testUpdate(t, r, s, plan, state)
+	if n := srv.count("PUT", ""); n != 2 {
+		t.Fatalf("value change sent %d PUTs in total, want 2", n)
+	}
+	if got := srv.keys("team")["db_password"]; got != "rotated" {
+		t.Errorf("stored value = %q, want %q", got, "rotated")
+	}
+}
+
+func TestSecretResourceIDIsStableAcrossNamespaceSpellings(t *testing.T) {
+	for _, ns := range []string{"team/prod", "/team/prod/", "team//prod"} {
+		srv := newFakeServer(t)
+		r := &SecretResource{client: newTestClient(t, srv.URL, Config{})}
+		s := resourceSchema(t, r)
+
+		state := testCreate(t, r, s, tfObject(t, s, map[string]tftypes.Value{
+			"namespace": tfString(ns),
+			"key":       tfString("db_password"),
+			"value":     tfString("s3cret"),
+		}))
+		if got, want := attrString(t, state, "id"), "team/prod/db_password"; got != want {
+			t.Errorf("namespace %q: id = %q, want %q", ns, got, want)
+		}
+		if got := srv.count("PUT", "/v2/configurations/team/prod"); got != 1 {
+			t.Errorf("namespace %q: %d PUTs to the normalized path, want 1", ns, got)
+		}
+		if got := attrString(t, testRead(t, r, s, state), "id"); got != "team/prod/db_password" {
+			t.Errorf("namespace %q: id after refresh = %q", ns, got)
+		}
+	}
+}
+
+func TestSecretResourceDeleteBlockedByProtectedTag(t *testing.T) {
+	srv := newFakeServer(t)
+	r := &SecretResource{client: newTestClient(t, srv.URL, Config{ProtectedTag: "protected=true"})}
+	s := resourceSchema(t, r)
+
+	state := testCreate(t, r, s, tfObject(t, s, map[string]tftypes.Value{
+		"namespace": tfString("team"),
+		"key":       tfString("db_password"),
+		"value":     tfString("s3cret"),
+	}))
+	// Tagged outside Terraform, so only the server knows.
+	srv.put("team", "db_password", "s3cret", map[string]string{"protected": "true"})
+
+	diags := testDeleteDiags(r, s, state)
+	if !diags.HasError() || diags[0].Summary() != "Secret is protected" {
+		t.Fatalf("delete diagnostics = %v, want a protected error", diags)
+	}
+	if _, ok := srv.keys("team")["db_password"]; !ok {
+		t.Fatal("protected secret was deleted")
+	}
+
+	forced := withAttrs(t, state, map[string]tftypes.Value{"force_delete": tfBool(true)})
+	failOnError(t, "forced delete", testDeleteDiags(r, s, forced))
+	if _, ok := srv.keys("team")["db_password"]; ok {
+		t.Error("force_delete did not delete the secret")
+	}
+}
+
+func TestSecretResourceRenameFromIsClearedOnceRemoved(t *testing.T) {
+	srv := newFakeServer(t)
+	r := &SecretResource{client: newTestClient(t, srv.URL, Config{})}
+	s := resourceSchema(t, r)
+
+	base := map[string]tftypes.Value{
+		"namespace": tfString("team"),
+		"key":       tfString("old_name"),
+		"value":     tfString("s3cret"),
+	}
+	state := testCreate(t, r, s, tfObject(t, s, base))
+
+	base["key"] = tfString("new_name")
+	base["rename_from"] = tfString("old_name")
+	config := tfObject(t, s, base)
+	state = testUpdate(t, r, s, testPlan(t, r, s, config, state), state)
+	if got, want := srv.keys("team"), map[string]string{"new_name": "s3cret"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("namespace holds %v after rename, want %v", got, want)
+	}
+
+	delete(base, "rename_from")
+	puts := srv.count("PUT", "")
+	plan := testPlan(t, r, s, tfObject(t, s, base), state)
+	if got := attrString(t, plan, "rename_from"); got != "" {
+		t.Fatalf("planned rename_from = %q, want null", got)
+	}
+	state = testUpdate(t, r, s, plan, state)
+	if got := attrString(t, state, "rename_from"); got != "" {
+		t.Errorf("rename_from in state = %q, want null", got)
+	}
+	if n := srv.count("PUT", ""); n != puts {
+		t.Errorf("clearing rename_from sent %d PUTs", n-puts)
+	}
+}
+
+func TestSecretResourceReadDetectsValueDrift(t *testing.T) {
+	srv := newFakeServer(t)
+	r := &SecretResource{client: newTestClient(t, srv.URL, Config{})}
+	s := resourceSchema(t, r)
+
+	config := tfObject(t, s, map[string]tftypes.Value{
+		"namespace": tfString("team"),
+		"key":       tfString("db_password"),
+		"value":     tfString("s3cret"),
+	})
+	state := testCreate(t, r, s, config)
+	written := attrString(t, state, "value_sha256")
+
+	srv.put("team", "db_password", "changed by hand", nil)
+	state = testRead(t, r, s, state)
+	if got, want := attrString(t, state, "value_sha256"), valueSHA256("changed by hand"); got != want {
+		t.Fatalf("value_sha256 after drift = %s, want the hash of the stored value %s", got, want)
+	}
+
+	plan := testPlan(t, r, s, config, state)
+	if got := attrString(t, plan, "value_sha256"); got != written {
+		t.Fatalf("planned value_sha256 = %s, want %s", got, written)
+	}
+	state = testUpdate(t, r, s, plan, state)
+	if got := srv.keys("team")["db_password"]; got != "s3cret" {
+		t.Errorf("stored value after apply = %q, want the configured value restored", got)
+	}
+	if got := attrString(t, state, "value_sha256"); got != written {
+		t.Errorf("value_sha256 after apply = %s, want %s", got, written)
+	}
+}
+
+func TestSecretResourceImportOfTransformedValueIsStable(t *testing.T) {
+	srv := newFakeServer(t)
+	srv.put("team", "cert", "YWJj", nil) // base64 of "abc"
+	r := &SecretResource{client: newTestClient(t, srv.URL, Config{})}
+	s := resourceSchema(t, r)
+
+	imported := tfObject(t, s, map[string]tftypes.Value{
+		"id":        tfString("team/cert"),
+		"namespace": tfString("team"),
+		"key":       tfString("cert"),
+	})
+	state := testRead(t, r, s, imported)
+
+	config := tfObject(t, s, map[string]tftypes.Value{
+		"namespace":       tfString("team"),
+		"key":             tfString("cert"),
+		"value":           tfString("abc"),
+		"value_transform": tftypes.NewValue(tftypes.List{ElementType: tftypes.String}, []tftypes.Value{tfString("base64encode")}),
+	})
+	for i := 0; i < 2; i++ {
+		plan := testPlan(t, r, s, config, state)
+		if got, want := attrString(t, plan, "value_sha256"), attrString(t, state, "value_sha256"); got != want {
+			t.Errorf("apply %d: planned value_sha256 = %s, want %s from state", i+1, got, want)
+		}
+		state = testRead(t, r, s, testUpdate(t, r, s, plan, state))
+	}
+	if n := srv.count("PUT", ""); n != 0 {
+		t.Errorf("applying the imported secret sent %d PUTs, want none", n)
+	}
+	if got := srv.keys("team")["cert"]; got != "YWJj" {
+		t.Errorf("stored value = %q, want it unchanged", got)
+	}
+}
+
+func TestSecretResourceReadDetectsLabelDrift(t *testing.T) {
+	srv := newFakeServer(t)
+	r := &SecretResource{client: newTestClient(t, srv.URL, Config{})}
+	s := resourceSchema(t, r)
+
+	state := testCreate(t, r, s, tfObject(t, s, map[string]tftypes.Value{
+		"namespace": tfString("team"),
+		"key":       tfString("db_password"),
+		"value":     tfString("s3cret"),
+		"labels":    tfStringMap(map[string]string{"tier": "gold"}),
+	}))
+	srv.setLabels("team", "db_password", map[string]string{"tier": "silver"})
+
+	state = testRead(t, r, s, state)
+	vals := map[string]tftypes.Value{}
+	if err := state.As(&vals); err != nil {
+		t.Fatal(err)
+	}
+	if want := tfStringMap(map[string]string{"tier": "silver"}); !vals["labels"].Equal(want) {
+		t.Errorf("labels after refresh = %v, want %v", vals["labels"], want)
+	}
+}
+
+// tfTimeouts returns a timeouts block value of s with the given entries set.
+func tfTimeouts(t *testing.T, s resSchema.Schema, entries map[string]string) tftypes.Value {
+	t.Helper()
+	typ := s.Type().TerraformType(context.Background()).(tftypes.Object).AttributeTypes["timeouts"].(tftypes.Object)
+	vals := map[string]tftypes.Value{}
+	for name := range typ.AttributeTypes {
+		vals[name] = tftypes.NewValue(tftypes.String, nil)
+	}
+	for name, v := range entries {
+		vals[name] = tfString(v)
+	}
+	return tftypes.NewValue(typ, vals)
+}
+
+func TestSecretResourceTimeoutsBoundWholeOperations(t *testing.T) {
+	srv := newFakeServer(t)
+	// Every request takes longer than request_timeout.
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+		srv.serve(w, req)
+	}))
+	defer slow.Close()
+	r := &SecretResource{client: newTestClient(t, slow.URL, Config{RequestTimeout: 20 * time.Millisecond})}
+	s := resourceSchema(t, r)
+	config := func(timeouts map[string]string) tftypes.Value {
+		return tfObject(t, s, map[string]tftypes.Value{
+			"namespace": tfString("team"),
+			"key":       tfString("db_password"),
+			"value":     tfString("s3cret"),
+			"timeouts":  tfTimeouts(t, s, timeouts),
+		})
+	}
+
+	if _, diags := testCreateDiags(r, s, config(nil)); !diags.HasError() {
+		t.Fatal("create without timeouts outlasted request_timeout")
+	}
+	state := testCreate(t, r, s, config(map[string]string{"create": "5s"}))
+	if got := srv.keys("team")["db_password"]; got != "s3cret" {
+		t.Errorf("stored value = %q, want %q", got, "s3cret")
+	}
+
+	// A deadline shorter than the operation still cuts it off.
+	short := withAttrs(t, state, map[string]tftypes.Value{"timeouts": tfTimeouts(t, s, map[string]string{"delete": "10ms"})})
+	if diags := testDeleteDiags(r, s, short); !diags.HasError() {
+		t.Error("delete outlasted timeouts.delete")
+	}
+}
+
+func TestSecretResourceValidatesTimeouts(t *testing.T) {
+	r := &SecretResource{}
+	s := resourceSchema(t, r)
+	config := tfObject(t, s, map[string]tftypes.Value{
+		"namespace": tfString("team"),
+		"key":       tfString("db_password"),
+		"value":     tfString("s3cret"),
+		"timeouts":  tfTimeouts(t, s, map[string]string{"create": "10m", "update": "soon", "delete": "-1m"}),
+	})
+	resp := &resource.ValidateConfigResponse{}
+	r.ValidateConfig(context.Background(), resource.ValidateConfigRequest{Config: tfsdk.Config{Schema: s, Raw: config}}, resp)
+	var invalid []string
+	for _, d := range resp.Diagnostics.Errors() {
+		if d, ok := d.(diag.DiagnosticWithPath); ok {
+			invalid = append(invalid, d.Path().String())
+		}
+	}
+	if want := []string{"timeouts.update", "timeouts.delete"}; !reflect.DeepEqual(invalid, want) {
+		t.Errorf("invalid timeouts reported at %v, want %v", invalid, want)
+	}
+}
+
+func TestSecretResourceImportOfHierarchicalNamespaces(t *testing.T) {
+	s := resourceSchema(t, &SecretResource{})
+	tests := []struct {
+		id, ns, key string
+		version     string
+	}{
+		{"team/prod/db_password", "team/prod", "db_password", ""},
+		{"/team/prod/db_password", "team/prod", "db_password", ""},
+		{"team//prod/key", "team/prod", "key", ""},
+		{"team/prod/key@3", "team/prod", "key", "3"},
+	}
+	for _, tt := range tests {
+		state, private, errs := testImport(t, s, "yggdrasil_secret", tt.id)
+		if errs != nil {
+			t.Errorf("import %q: %v", tt.id, errs)
+			continue
+		}
+		if got := attrString(t, state, "namespace"); got != tt.ns {
+			t.Errorf("import %q: namespace = %q, want %q", tt.id, got, tt.ns)
+		}
+		if got := attrString(t, state, "key"); got != tt.key {
+			t.Errorf("import %q: key = %q, want %q", tt.id, got, tt.key)
+		}
+		if got, want := attrString(t, state, "id"), tt.ns+"/"+tt.key; got != want {
+			t.Errorf("import %q: id = %q, want %q", tt.id, got, want)
+		}
+		if pinned := strings.Contains(string(private), `"`+importVersionKey+`"`); pinned != (tt.version != "") {
+			t.Errorf("import %q: private state %s, want version pinned = %t", tt.id, private, tt.version != "")
+		}
+	}
+
+	for _, id := range []string{"/team/prod/", "db_password", "/db_password", "team/prod/key@0"} {
+		if _, _, errs := testImport(t, s, "yggdrasil_secret", id); len(errs) == 0 {
+			t.Errorf("import %q succeeded, want an invalid import ID", id)
+		}
+	}
+}
+
+func TestSecretResourceRefreshKeepsWrittenVersion(t *testing.T) {
+	srv := newFakeServer(t)
+	r := &SecretResource{client: newTestClient(t, srv.URL, Config{})}
+	s := resourceSchema(t, r)
+
+	state := testCreate(t, r, s, tfObject(t, s, map[string]tftypes.Value{
+		"namespace": tfString("team"),
+		"key":       tfString("db_password"),
+		"value":     tfString("s3cret"),
+	}))
+	version := func(v tftypes.Value) tftypes.Value {
+		vals := map[string]tftypes.Value{}
+		if err := v.As(&vals); err != nil {
+			t.Fatal(err)
+		}
+		return vals["version"]
+	}
+	written, updatedAt := version(state), attrString(t, state, "updated_at")
+
+	// The fake server's namespace reads report neither a version nor a
+	// modification time.
+	refreshed := testRead(t, r, s, state)
+	if got := version(refreshed); !got.Equal(written) {
+		t.Errorf("version after refresh = %v, want %v from the write", got, written)
+	}
+	if got := attrString(t, refreshed, "updated_at"); got != updatedAt {
+		t.Errorf("updated_at after refresh = %q, want %q from the write", got, updatedAt)
+	}
+}
+
+func TestSecretResourceEmptyServerLabelsKeepUnsetLabelsNull(t *testing.T) {
+	srv := newFakeServer(t)
+	r := &SecretResource{client: newTestClient(t, srv.URL, Config{})}
+	s := resourceSchema(t, r)
+
+	config := tfObject(t, s, map[string]tftypes.Value{
+		"namespace": tfString("team"),
+		"key":       tfString("db_password"),
+		"value":     tfString("s3cret"),
+	})
+	state := testCreate(t, r, s, config)
+	srv.setLabels("team", "db_password", map[string]string{})
+
+	state = testRead(t, r, s, state)
+	vals := map[string]tftypes.Value{}
+	if err := state.As(&vals); err != nil {
+		t.Fatal(err)
+	}
+	if !vals["labels"].IsNull() {
+		t.Errorf("labels after refresh = %v, want null like the configuration", vals["labels"])
+	}
+}
+
+func TestSecretResourceChecksSoftDeletesOnlyWhenConfigured(t *testing.T) {
+	srv := newFakeServer(t)
+	r := &SecretResource{client: newTestClient(t, srv.URL, Config{})}
+	s := resourceSchema(t, r)
+
+	for _, soft := range []bool{false, true} {
+		state := testCreate(t, r, s, tfObject(t, s, map[string]tftypes.Value{
+			"namespace":   tfString("team"),
+			"key":         tfString("a b?c"),
+			"value":       tfString("s3cret"),
+			"soft_delete": tfBool(soft),
+		}))
+		failOnError(t, "delete", testDeleteDiags(r, s, withAttrs(t, state, map[string]tftypes.Value{"soft_delete": tfBool(false)})))
+
+		before := srv.count(http.MethodGet, "/deleted/a b?c")
+		if got := testRead(t, r, s, state); !got.IsNull() {
+			t.Errorf("soft_delete = %t: secret still in state after refresh", soft)
+		}
+		if n, want := srv.count(http.MethodGet, "/deleted/a b?c")-before, map[bool]int{false: 0, true: 1}[soft]; n != want {
+			t.Errorf("soft_delete = %t: %d soft-delete lookups, want %d", soft, n, want)
+		}
+	}
+}
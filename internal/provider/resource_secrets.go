@@ -0,0 +1,281 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	resSchema "github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	stringplanmodifier "github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	tfTypes "github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var _ resource.Resource = &SecretsResource{}
+var _ resource.ResourceWithValidateConfig = &SecretsResource{}
+
+const (
+	manageModeExclusive = "exclusive"
+	manageModeMerge     = "merge"
+)
+
+func NewSecretsResource() resource.Resource {
+	return &SecretsResource{}
+}
+
+// SecretsResource manages many keys of one namespace. In exclusive mode it
+// owns the whole namespace and deletes keys missing from config; in merge
+// mode it only touches the keys it wrote itself (managed_keys).
+type SecretsResource struct {
+	client *APIClient
+}
+
+type SecretsResourceModel struct {
+	ID          tfTypes.String `tfsdk:"id"`
+	Namespace   tfTypes.String `tfsdk:"namespace"`
+	Secrets     tfTypes.Map    `tfsdk:"secrets"`
+	ManageMode  tfTypes.String `tfsdk:"manage_mode"`
+	ManagedKeys tfTypes.List   `tfsdk:"managed_keys"`
+}
+
+func (r *SecretsResource) Metadata(_ context.Context, _ resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = "yggdrasil_secrets"
+}
+
+func (r *SecretsResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = resSchema.Schema{
+		Description: "Manages a set of keys in one namespace with a single request per apply.",
+		Attributes: map[string]resSchema.Attribute{
+			"id": resSchema.StringAttribute{
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"namespace": resSchema.StringAttribute{
+				Required: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"secrets": resSchema.MapAttribute{
+				ElementType: tfTypes.StringType,
+				Required:    true,
+				Sensitive:   true,
+				Description: "Key/value pairs to store in the namespace.",
+			},
+			"manage_mode": resSchema.StringAttribute{
+				Optional:    true,
+				Description: "`exclusive` (default): keys in the namespace that are not in `secrets` are deleted. `merge`: only the keys in `secrets` are written, and only keys this resource wrote are ever deleted, so several resources can share a namespace.",
+			},
+			"managed_keys": resSchema.ListAttribute{
+				ElementType: tfTypes.StringType,
+				Computed:    true,
+				Description: "Keys written by this resource, sorted. Removing a key from `secrets` deletes it from the namespace in either mode.",
+			},
+		},
+	}
+}
+
+func (r *SecretsResource) Configure(_ context.Context, req resource.ConfigureRequest, _ *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	r.client = req.ProviderData.(*APIClient)
+}
+
+func (r *SecretsResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var cfg SecretsResourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &cfg)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	checkMapNulls(&resp.Diagnostics, path.Root("secrets"), cfg.Secrets)
+	if cfg.ManageMode.IsNull() || cfg.ManageMode.IsUnknown() {
+		return
+	}
+	if m := cfg.ManageMode.ValueString(); m != manageModeExclusive && m != manageModeMerge {
+		resp.Diagnostics.AddAttributeError(path.Root("manage_mode"), "Invalid manage_mode",
+			fmt.Sprintf("manage_mode must be %q or %q, got %q", manageModeExclusive, manageModeMerge, m))
+	}
+}
+
+func (r *SecretsResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan SecretsResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, reportUnknown := collectUnknownFields(ctx)
+	defer reportUnknown(&resp.Diagnostics)
+	if r.apply(ctx, &plan, nil, &resp.Diagnostics) {
+		resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+	}
+}
+
+func (r *SecretsResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state SecretsResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, reportUnknown := collectUnknownFields(ctx)
+	defer reportUnknown(&resp.Diagnostics)
+
+	remote, err := r.client.ListSecrets(ctx, state.Namespace.ValueString())
+	if err != nil {
+		addAPIError(&resp.Diagnostics, "Read failed", err)
+		return
+	}
+	if remote == nil {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	secrets := mapFromTF(ctx, &resp.Diagnostics, path.Root("secrets"), state.Secrets)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	for k := range secrets {
+		if v, ok := remote[k]; ok {
+			secrets[k] = v
+		} else {
+			delete(secrets, k)
+		}
+	}
+	if manageMode(state) == manageModeExclusive {
+		// Everything in the namespace is ours, so unknown keys show up as drift to delete.
+		for k, v := range remote {
+			secrets[k] = v
+		}
+	}
+
+	secretsVal, diags := tfTypes.MapValueFrom(ctx, tfTypes.StringType, secrets)
+	resp.Diagnostics.Append(diags...)
+	state.Secrets = secretsVal
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+func (r *SecretsResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan, state SecretsResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, reportUnknown := collectUnknownFields(ctx)
+	defer reportUnknown(&resp.Diagnostics)
+	if r.apply(ctx, &plan, &state, &resp.Diagnostics) {
+		resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+	}
+}
+
+func (r *SecretsResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state SecretsResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	// Only the keys this resource wrote are removed, whatever the mode.
+	var keys []string
+	resp.Diagnostics.Append(state.ManagedKeys.ElementsAs(ctx, &keys, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if err := r.client.WriteSecrets(ctx, state.Namespace.ValueString(), nil, keys); err != nil {
+		addAPIError(&resp.Diagnostics, "Delete failed", err)
+	}
+}
+
+// apply writes the planned secrets and deletes the keys that are no longer
+// wanted: in exclusive mode every other key in the namespace, in merge mode
+// only keys from prior's managed_keys. prior is nil on create.
+//
+// It reports whether plan should be saved as the new state. After a partial
+// batch failure, plan is rewritten to what was actually applied (prior plus
+// the keys that succeeded) and saved alongside an error listing the failures,
+// so the next apply retries only those keys.
+func (r *SecretsResource) apply(ctx context.Context, plan, prior *SecretsResourceModel, diags *diag.Diagnostics) bool {
+	ns := plan.Namespace.ValueString()
+	values := mapFromTF(ctx, diags, path.Root("secrets"), plan.Secrets)
+	if diags.HasError() {
+		return false
+	}
+
+	var stale []string
+	if manageMode(*plan) == manageModeExclusive {
+		remote, err := r.client.ListSecrets(ctx, ns)
+		if err != nil {
+			addAPIError(diags, "Listing namespace failed", err)
+			return false
+		}
+		for k := range remote {
+			if _, ok := values[k]; !ok {
+				stale = append(stale, k)
+			}
+		}
+	} else if prior != nil {
+		var managed []string
+		diags.Append(prior.ManagedKeys.ElementsAs(ctx, &managed, false)...)
+		if diags.HasError() {
+			return false
+		}
+		for _, k := range managed {
+			if _, ok := values[k]; !ok {
+				stale = append(stale, k)
+			}
+		}
+	}
+	sort.Strings(stale)
+
+	err := r.client.WriteSecrets(ctx, ns, values, stale)
+	var batchErr *BatchError
+	if err != nil && !errors.As(err, &batchErr) {
+		addAPIError(diags, "Write failed", err)
+		return false
+	}
+
+	applied := values
+	if batchErr != nil {
+		applied = map[string]string{}
+		if prior != nil {
+			applied = mapFromTF(ctx, diags, path.Root("secrets"), prior.Secrets)
+		}
+		for k, v := range values {
+			if _, failed := batchErr.Failed[k]; !failed {
+				applied[k] = v
+			}
+		}
+		for _, k := range stale {
+			if _, failed := batchErr.Failed[k]; !failed {
+				delete(applied, k)
+			}
+		}
+		secrets, d := tfTypes.MapValueFrom(ctx, tfTypes.StringType, applied)
+		diags.Append(d...)
+		plan.Secrets = secrets
+		diags.AddError("Write partially failed", batchErr.Error()+"\n\nThe keys that were written are saved in state; the next apply retries the rest.")
+	}
+
+	keys := make([]string, 0, len(applied))
+	for k := range applied {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	managed, d := tfTypes.ListValueFrom(ctx, tfTypes.StringType, keys)
+	diags.Append(d...)
+	plan.ManagedKeys = managed
+	plan.ID = tfTypes.StringValue(normalizeNamespace(ns))
+	return true
+}
+
+func manageMode(m SecretsResourceModel) string {
+	if m.ManageMode.ValueString() == manageModeMerge {
+		return manageModeMerge
+	}
+	return manageModeExclusive
+}
@@ -0,0 +1,113 @@
+package provider
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+// nullKeys returns the sorted keys that configs deletes.
+func nullKeys(configs map[string]*string) []string {
+	keys := []string{}
+	for k, v := range configs {
+		if v == nil {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// secretsPlan is the plan for a yggdrasil_secrets update of prior to secrets.
+func secretsPlan(t *testing.T, prior tftypes.Value, secrets map[string]string) tftypes.Value {
+	t.Helper()
+	return withAttrs(t, prior, map[string]tftypes.Value{
+		"secrets":      tfStringMap(secrets),
+		"managed_keys": tftypes.NewValue(tftypes.List{ElementType: tftypes.String}, tftypes.UnknownValue),
+	})
+}
+
+func TestSecretsResourceExclusiveDeletesRemovedAndForeignKeys(t *testing.T) {
+	srv := newFakeServer(t)
+	srv.put("app", "foreign", "x", nil)
+	r := &SecretsResource{client: newTestClient(t, srv.URL, Config{})}
+	s := resourceSchema(t, r)
+
+	state := testCreate(t, r, s, tfObject(t, s, map[string]tftypes.Value{
+		"namespace": tfString("app"),
+		"secrets":   tfStringMap(map[string]string{"a": "1", "b": "2"}),
+	}))
+	puts := srv.puts(t)
+	if got, want := nullKeys(puts[len(puts)-1]), []string{"foreign"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("create deleted %q, want %q", got, want)
+	}
+
+	srv.put("app", "added_outside", "y", nil)
+	testUpdate(t, r, s, secretsPlan(t, state, map[string]string{"a": "1"}), state)
+	puts = srv.puts(t)
+	if got, want := nullKeys(puts[len(puts)-1]), []string{"added_outside", "b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("update deleted %q, want %q", got, want)
+	}
+	if got, want := srv.keys("app"), map[string]string{"a": "1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("namespace holds %v, want %v", got, want)
+	}
+}
+
+func TestSecretsResourceMergeDeletesOnlyManagedKeys(t *testing.T) {
+	srv := newFakeServer(t)
+	srv.put("app", "foreign", "x", nil)
+	r := &SecretsResource{client: newTestClient(t, srv.URL, Config{})}
+	s := resourceSchema(t, r)
+
+	state := testCreate(t, r, s, tfObject(t, s, map[string]tftypes.Value{
+		"namespace":   tfString("app"),
+		"manage_mode": tfString("merge"),
+		"secrets":     tfStringMap(map[string]string{"a": "1", "b": "2"}),
+	}))
+	puts := srv.puts(t)
+	if got := nullKeys(puts[len(puts)-1]); len(got) != 0 {
+		t.Fatalf("create deleted %q, want nothing", got)
+	}
+
+	state = testUpdate(t, r, s, secretsPlan(t, state, map[string]string{"a": "1"}), state)
+	puts = srv.puts(t)
+	if got, want := nullKeys(puts[len(puts)-1]), []string{"b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("update deleted %q, want %q", got, want)
+	}
+
+	failOnError(t, "delete", testDeleteDiags(r, s, state))
+	puts = srv.puts(t)
+	last := puts[len(puts)-1]
+	if got, want := nullKeys(last), []string{"a"}; !reflect.DeepEqual(got, want) || len(last) != len(want) {
+		t.Errorf("delete sent %v, want only deletes of %q", last, want)
+	}
+	if got, want := srv.keys("app"), map[string]string{"foreign": "x"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("namespace holds %v after delete, want %v", got, want)
+	}
+}
+
+func TestSecretsResourceReadWithGraphQLKeepsState(t *testing.T) {
+	srv := newFakeServer(t)
+	r := &SecretsResource{client: newTestClient(t, srv.URL, Config{})}
+	s := resourceSchema(t, r)
+	state := testCreate(t, r, s, tfObject(t, s, map[string]tftypes.Value{
+		"namespace": tfString("app"),
+		"secrets":   tfStringMap(map[string]string{"a": "1"}),
+	}))
+
+	r.client = newTestClient(t, srv.URL, Config{Protocol: protocolGraphQL, GraphQLEndpoint: srv.URL + "/graphql"})
+	req := resource.ReadRequest{State: tfsdk.State{Schema: s, Raw: state}}
+	resp := &resource.ReadResponse{State: tfsdk.State{Schema: s, Raw: state}}
+	r.Read(context.Background(), req, resp)
+	if !resp.Diagnostics.HasError() {
+		t.Error("read succeeded, want a protocol error")
+	}
+	if resp.State.Raw.IsNull() {
+		t.Error("read removed the resource from state")
+	}
+}
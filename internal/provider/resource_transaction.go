@@ -0,0 +1,255 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	resSchema "github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	stringplanmodifier "github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	tfTypes "github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var _ resource.Resource = &TransactionResource{}
+
+func NewTransactionResource() resource.Resource {
+	return &TransactionResource{}
+}
+
+// TransactionResource applies a group of upserts/deletes, possibly spanning
+// namespaces, all-or-nothing. It uses the server's transaction endpoint when
+// available and otherwise applies the operations in order, undoing the ones
+// already committed if a later one fails.
+type TransactionResource struct {
+	client *APIClient
+}
+
+type TransactionResourceModel struct {
+	ID         tfTypes.String              `tfsdk:"id"`
+	Operations []TransactionOperationModel `tfsdk:"operations"`
+	Committed  tfTypes.List                `tfsdk:"committed"`
+}
+
+type TransactionOperationModel struct {
+	Action    tfTypes.String `tfsdk:"action"`
+	Namespace tfTypes.String `tfsdk:"namespace"`
+	Key       tfTypes.String `tfsdk:"key"`
+	Value     tfTypes.String `tfsdk:"value"`
+}
+
+func (r *TransactionResource) Metadata(_ context.Context, _ resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = "yggdrasil_transaction"
+}
+
+func (r *TransactionResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = resSchema.Schema{
+		Description: "Applies a group of secret upserts and deletes atomically. Destroying this resource does not undo the operations.",
+		Attributes: map[string]resSchema.Attribute{
+			"id": resSchema.StringAttribute{
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"operations": resSchema.ListNestedAttribute{
+				Required:    true,
+				Description: "Operations to apply, in order.",
+				NestedObject: resSchema.NestedAttributeObject{
+					Attributes: map[string]resSchema.Attribute{
+						"action": resSchema.StringAttribute{
+							Optional:    true,
+							Description: "`upsert` (default) or `delete`.",
+						},
+						"namespace": resSchema.StringAttribute{
+							Required: true,
+						},
+						"key": resSchema.StringAttribute{
+							Required: true,
+						},
+						"value": resSchema.StringAttribute{
+							Optional:    true,
+							Sensitive:   true,
+							Description: "Value to write. Required for `upsert`.",
+						},
+					},
+				},
+			},
+			"committed": resSchema.ListAttribute{
+				ElementType: tfTypes.StringType,
+				Computed:    true,
+				Description: "Operations committed by the last apply, as `action namespace/key`.",
+			},
+		},
+	}
+}
+
+func (r *TransactionResource) Configure(_ context.Context, req resource.ConfigureRequest, _ *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	r.client = req.ProviderData.(*APIClient)
+}
+
+func (r *TransactionResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan TransactionResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	r.apply(ctx, &plan, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *TransactionResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	// The transaction is a one-shot operation; the keys it touched are not tracked for drift.
+	var state TransactionResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+func (r *TransactionResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan TransactionResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	r.apply(ctx, &plan, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *TransactionResource) Delete(_ context.Context, _ resource.DeleteRequest, _ *resource.DeleteResponse) {
+	// Committed operations are not reverted on destroy.
+}
+
+// apply commits the planned operations and records them in m.Committed.
+func (r *TransactionResource) apply(ctx context.Context, m *TransactionResourceModel, diags *diag.Diagnostics) {
+	ops := make([]TransactionOp, 0, len(m.Operations))
+	ids := make([]string, 0, len(m.Operations))
+	for i, o := range m.Operations {
+		op := TransactionOp{
+			Action:    o.Action.ValueString(),
+			Namespace: o.Namespace.ValueString(),
+			Key:       o.Key.ValueString(),
+			Value:     o.Value.ValueString(),
+		}
+		if op.Action == "" {
+			op.Action = "upsert"
+		}
+		switch {
+		case op.Action != "upsert" && op.Action != "delete":
+			diags.AddError("Invalid operation", fmt.Sprintf("operations[%d]: action must be \"upsert\" or \"delete\", got %q", i, op.Action))
+		case op.Action == "upsert" && o.Value.IsNull():
+			diags.AddError("Invalid operation", fmt.Sprintf("operations[%d]: value is required for upsert", i))
+		}
+		ops = append(ops, op)
+		ids = append(ids, fmt.Sprintf("%s %s/%s", op.Action, op.Namespace, op.Key))
+	}
+	if diags.HasError() {
+		return
+	}
+
+	supported, err := r.client.CommitTransaction(ctx, ops)
+	switch {
+	case errors.Is(err, errCommitUnknown):
+		diags.AddError("Transaction outcome unknown", err.Error()+
+			"\n\nThe request failed after it was sent, so the server may have committed all of the operations or none. Check the keys before applying again.")
+		return
+	case err != nil:
+		addAPIError(diags, "Transaction failed", fmt.Errorf("no operations were committed: %w", err))
+		return
+	}
+	if !supported {
+		ids, err = r.applySequential(ctx, ops)
+		if err != nil {
+			addAPIError(diags, "Transaction failed", err)
+			return
+		}
+	}
+
+	elems := make([]attr.Value, 0, len(ids))
+	for _, id := range ids {
+		elems = append(elems, tfTypes.StringValue(id))
+	}
+	m.ID = tfTypes.StringValue(strings.Join(ids, ","))
+	m.Committed = tfTypes.ListValueMust(tfTypes.StringType, elems)
+}
+
+// applySequential applies ops one by one. When one fails, the already
+// committed ops are compensated in reverse order by restoring the value each
+// key had before (or deleting it if it did not exist).
+func (r *TransactionResource) applySequential(ctx context.Context, ops []TransactionOp) ([]string, error) {
+	type undo struct {
+		op   TransactionOp
+		prev *SecretResponse
+	}
+	var done []undo
+	var committed []string
+
+	for _, op := range ops {
+		prev, err := r.client.GetSecret(ctx, op.Namespace, op.Key)
+		if err == nil && prev != nil && prev.Tags == nil {
+			// Namespace reads may not carry tags; the rollback must restore them.
+			prev.Tags, err = r.client.GetSecretTags(ctx, op.Namespace, op.Key)
+		}
+		if err == nil {
+			if op.Action == "delete" {
+				err = r.client.DeleteSecret(ctx, op.Namespace, op.Key)
+			} else {
+				_, err = r.client.UpsertSecret(ctx, SecretPayload{Namespace: op.Namespace, Key: op.Key, Value: op.Value})
+			}
+		}
+		if err == nil {
+			done = append(done, undo{op: op, prev: prev})
+			committed = append(committed, fmt.Sprintf("%s %s/%s", op.Action, op.Namespace, op.Key))
+			continue
+		}
+
+		msg := fmt.Sprintf("%s %s/%s failed: %s", op.Action, op.Namespace, op.Key, err)
+		// Roll back even if the apply is being cancelled: stopping halfway
+		// would leave the partial state that the rollback exists to undo.
+		rbCtx := context.WithoutCancel(ctx)
+		var rollbackErrs []string
+		for i := len(done) - 1; i >= 0; i-- {
+			u := done[i]
+			var rbErr error
+			if u.prev == nil {
+				// The key did not exist before this apply, so protected_tag does not apply.
+				rbErr = r.client.DeleteSecret(withForceDelete(rbCtx), u.op.Namespace, u.op.Key)
+			} else {
+				_, rbErr = r.client.UpsertSecret(rbCtx, SecretPayload{
+					Namespace: u.op.Namespace,
+					Key:       u.op.Key,
+					Value:     u.prev.Value,
+					Tags:      u.prev.Tags,
+					Labels:    u.prev.Labels,
+				})
+			}
+			if rbErr != nil {
+				rollbackErrs = append(rollbackErrs, fmt.Sprintf("%s/%s: %s", u.op.Namespace, u.op.Key, rbErr))
+			}
+		}
+		if len(rollbackErrs) > 0 {
+			return nil, fmt.Errorf("%s\n\nCommitted before the failure: %s\nRollback FAILED for: %s",
+				msg, strings.Join(committed, ", "), strings.Join(rollbackErrs, "; "))
+		}
+		if len(committed) > 0 {
+			return nil, fmt.Errorf("%s\n\nRolled back: %s", msg, strings.Join(committed, ", "))
+		}
+		return nil, errors.New(msg)
+	}
+	return committed, nil
+}
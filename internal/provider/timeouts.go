@@ -0,0 +1,79 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	resSchema "github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	tfTypes "github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// The timeouts block mirrors the schema of terraform-plugin-framework-timeouts
+// (a single nested block of create/read/update/delete duration strings), so
+// configurations written for other providers carry over unchanged.
+
+// timeoutOps are the attributes of the timeouts block.
+var timeoutOps = []string{"create", "read", "update", "delete"}
+
+func timeoutsBlock() resSchema.SingleNestedBlock {
+	attrs := make(map[string]resSchema.Attribute, len(timeoutOps))
+	for _, op := range timeoutOps {
+		attrs[op] = resSchema.StringAttribute{
+			Optional:    true,
+			Description: fmt.Sprintf("Deadline for the whole %s, as a Go duration such as `10m`.", op),
+		}
+	}
+	return resSchema.SingleNestedBlock{
+		Attributes: attrs,
+		Description: "Deadlines for whole resource operations, across all of their API requests and retries. " +
+			"An operation with a deadline here is not also bounded by the provider's `request_timeout` or " +
+			"`adaptive_timeout`, so it can be given longer than those allow. Operations without one keep the provider's deadlines.",
+	}
+}
+
+// operationTimeout returns the duration configured for op in timeouts, or
+// zero when the block or the attribute is not set.
+func operationTimeout(timeouts tfTypes.Object, op string) (time.Duration, error) {
+	if timeouts.IsNull() || timeouts.IsUnknown() {
+		return 0, nil
+	}
+	v, ok := timeouts.Attributes()[op].(tfTypes.String)
+	if !ok || v.IsNull() || v.IsUnknown() {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(v.ValueString())
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("%q is not a positive duration (e.g. \"30s\", \"10m\")", v.ValueString())
+	}
+	return d, nil
+}
+
+// checkTimeouts reports every invalid duration in timeouts.
+func checkTimeouts(diags *diag.Diagnostics, timeouts tfTypes.Object) {
+	for _, op := range timeoutOps {
+		if _, err := operationTimeout(timeouts, op); err != nil {
+			diags.AddAttributeError(path.Root("timeouts").AtName(op), "Invalid timeout", err.Error())
+		}
+	}
+}
+
+type operationTimeoutKey struct{}
+
+// withOperationTimeout bounds ctx by the timeouts entry for op, if one is set.
+// The API requests made under it use that deadline instead of request_timeout
+// (see withTimeout), even from a context.WithoutCancel copy of ctx.
+func withOperationTimeout(ctx context.Context, diags *diag.Diagnostics, timeouts tfTypes.Object, op string) (context.Context, context.CancelFunc) {
+	d, err := operationTimeout(timeouts, op)
+	if err != nil {
+		diags.AddAttributeError(path.Root("timeouts").AtName(op), "Invalid timeout", err.Error())
+		return ctx, func() {}
+	}
+	if d == 0 {
+		return ctx, func() {}
+	}
+	deadline := time.Now().Add(d)
+	return context.WithDeadline(context.WithValue(ctx, operationTimeoutKey{}, deadline), deadline)
+}
@@ -4,6 +4,7 @@ package utils
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/url"
 	"regexp"
@@ -13,6 +14,7 @@ import (
 const (
 	RedactionMask = "****"
 	maxPreviewLen = 16
+	maxLogBodyLen = 4096
 )
 
 var sensitiveKeySubstr = []string{
@@ -106,6 +108,45 @@ func RedactURLQuery(raw string, extraSensitiveKeys ...string) string {
 	return u.String()
 }
 
+// sensitivePathSegments name path segments whose following segment carries a
+// credential, as in legacy routes like /v2/configurations/ns/token/<token>.
+var sensitivePathSegments = []string{
+	"token", "tokens", "secret", "secrets", "password", "passwd",
+	"apikey", "api_key", "api-key", "credential", "credentials",
+}
+
+// RedactURLPath masks path segments that follow a sensitive segment name or
+// that match any of the given patterns. The query string is left untouched.
+func RedactURLPath(raw string, patterns ...*regexp.Regexp) string {
+	u, err := url.Parse(raw)
+	if err != nil || u == nil {
+		return raw
+	}
+	segs := strings.Split(u.Path, "/")
+	rawSegs := make([]string, len(segs))
+	for i, seg := range segs {
+		rawSegs[i] = url.PathEscape(seg)
+		if seg == "" {
+			continue
+		}
+		masked := i > 0 && containsFold(sensitivePathSegments, segs[i-1])
+		for _, rx := range patterns {
+			if masked {
+				break
+			}
+			masked = rx.MatchString(seg)
+		}
+		if masked {
+			segs[i] = RedactionMask
+			rawSegs[i] = RedactionMask
+		}
+	}
+	u.Path = strings.Join(segs, "/")
+	// Keep the mask literal instead of letting url.URL escape it to %2A.
+	u.RawPath = strings.Join(rawSegs, "/")
+	return u.String()
+}
+
 func containsFold(list []string, s string) bool {
 	for _, x := range list {
 		if strings.EqualFold(x, s) {
@@ -168,6 +209,14 @@ func RedactBytesChain(body []byte) []byte {
 	return body
 }
 
+// LogPreview returns b as a string for logging, cut to a bounded length.
+func LogPreview(b []byte) string {
+	if len(b) <= maxLogBodyLen {
+		return string(b)
+	}
+	return fmt.Sprintf("%s… (%d more bytes)", b[:maxLogBodyLen], len(b)-maxLogBodyLen)
+}
+
 func SafeKVString(fields map[string]any) string {
 	var buf bytes.Buffer
 	first := true